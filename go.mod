@@ -1,15 +1,26 @@
 module github.com/MatsuoTakuro/starwars
 
-go 1.17
+go 1.21
 
 require (
 	github.com/99designs/gqlgen v0.17.2
+	github.com/agnivade/levenshtein v1.1.0
 	github.com/vektah/gqlparser/v2 v2.4.0
+	golang.org/x/text v0.14.0
+	modernc.org/sqlite v1.34.5
 )
 
 require (
-	github.com/agnivade/levenshtein v1.1.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/hashicorp/golang-lru v0.5.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mitchellh/mapstructure v1.4.1 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
 )
@@ -0,0 +1,112 @@
+// Package limits guards the server against operations too expensive to
+// run: it prices fields for gqlgen's complexity limit and bounds the depth
+// of selection sets.
+package limits
+
+import (
+	"math"
+	"time"
+
+	"github.com/MatsuoTakuro/starwars/graph/generated"
+	"github.com/MatsuoTakuro/starwars/graph/model"
+)
+
+const (
+	// listSize is the length assumed for lists that take no size argument.
+	listSize = 5
+	// searchCost is the price of scanning the dataset for a search.
+	searchCost = 10
+	// maxPageSize caps the page size a connection is priced for. Pages
+	// asking for more are priced as this many edges, which is already far
+	// beyond any complexity limit worth configuring.
+	maxPageSize = 1000
+)
+
+// Complexity prices the fields returning lists by the number of items
+// they are expected to yield; every other field costs one plus its
+// selections, gqlgen's default.
+func Complexity() generated.ComplexityRoot {
+	var c generated.ComplexityRoot
+
+	c.Human.Friends = list
+	c.Human.FriendsConnection = connection
+	c.Human.Starships = list
+	c.Human.StarshipsConnection = connection
+	c.Human.Vehicles = list
+	c.Droid.Friends = list
+	c.Droid.FriendsConnection = connection
+	c.Droid.Starships = list
+	c.Droid.StarshipsConnection = connection
+	c.Droid.Vehicles = list
+	c.Human.Films = list
+	c.Droid.Films = list
+	c.Film.Heroes = list
+	c.Planet.Residents = list
+	c.Starship.Pilots = list
+	c.Starship.Track = func(childComplexity int, _ *time.Time, _ *time.Time) int {
+		return list(childComplexity)
+	}
+	c.Starship.Revisits = list
+	c.Vehicle.Pilots = list
+
+	c.Query.Films = list
+	c.Query.Planets = list
+	c.Query.Vehicles = list
+	c.Query.StarshipsNear = func(childComplexity int, _ int, _ int, _ float64) int {
+		return list(childComplexity)
+	}
+	c.Query.StarshipsInRegion = func(childComplexity int, _ model.BoundingBox) int {
+		return list(childComplexity)
+	}
+	c.Query.Heroes = func(childComplexity int, _ *model.Episode) int {
+		return list(childComplexity)
+	}
+	c.Query.Reviews = func(childComplexity int, _ model.Episode, _ *time.Time) int {
+		return list(childComplexity)
+	}
+	c.Query.ReviewsConnection = func(childComplexity int, _ model.Episode, _ *time.Time, first *int, after *string, last *int, before *string) int {
+		return connection(childComplexity, first, after, last, before)
+	}
+	c.Query.Search = func(childComplexity int, _ string, _ []model.SearchType) int {
+		return add(searchCost, list(childComplexity))
+	}
+	c.Query.SearchConnection = func(childComplexity int, _ string, _ []model.SearchType, first *int, after *string, last *int, before *string) int {
+		return add(searchCost, connection(childComplexity, first, after, last, before))
+	}
+	return c
+}
+
+func list(childComplexity int) int {
+	return add(1, mul(listSize, childComplexity))
+}
+
+// connection prices a page by its requested size. The edges and node lists
+// of the connection are covered by this multiplier.
+func connection(childComplexity int, first *int, _ *string, last *int, _ *string) int {
+	n := listSize
+	switch {
+	case first != nil:
+		n = *first
+	case last != nil:
+		n = *last
+	}
+	n = max(0, min(n, maxPageSize))
+	return add(1, mul(n, childComplexity))
+}
+
+// add and mul saturate at math.MaxInt rather than wrap around, so that a
+// price too large to represent still exceeds the limit. Both expect
+// non-negative operands, which complexities are.
+func add(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func mul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
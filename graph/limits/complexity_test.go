@@ -0,0 +1,39 @@
+package limits
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConnection(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name            string
+		childComplexity int
+		first, last     *int
+		want            int
+	}{
+		{name: "default page", childComplexity: 2, want: 1 + listSize*2},
+		{name: "first", childComplexity: 2, first: ptr(3), want: 7},
+		{name: "last", childComplexity: 2, last: ptr(4), want: 9},
+		{name: "negative first", childComplexity: 2, first: ptr(-3), want: 1},
+		{name: "huge first", childComplexity: 2, first: ptr(math.MaxInt), want: 1 + maxPageSize*2},
+		{name: "huge child", childComplexity: math.MaxInt / 2, first: ptr(10), want: math.MaxInt},
+		{name: "saturated child", childComplexity: math.MaxInt, first: ptr(1), want: math.MaxInt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := connection(tt.childComplexity, tt.first, nil, tt.last, nil); got != tt.want {
+				t.Errorf("connection() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaturatingSearch(t *testing.T) {
+	first := math.MaxInt
+	if got := Complexity().Query.SearchConnection(math.MaxInt, "", nil, &first, nil, nil, nil); got != math.MaxInt {
+		t.Errorf("SearchConnection() = %d, want %d", got, math.MaxInt)
+	}
+}
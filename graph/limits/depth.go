@@ -0,0 +1,106 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// DepthLimit rejects operations whose selections nest deeper than Limit
+// fields before they are executed. Introspection fields count like any
+// other, so the limit must leave room for the introspection queries of
+// tools like the playground.
+type DepthLimit struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = &DepthLimit{}
+
+// FixedDepthLimit sets a depth limit that does not change.
+func FixedDepthLimit(limit int) *DepthLimit {
+	return &DepthLimit{Limit: limit}
+}
+
+func (d DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d *DepthLimit) Validate(graphql.ExecutableSchema) error {
+	if d.Limit <= 0 {
+		return fmt.Errorf("DepthLimit limit must be positive, got %d", d.Limit)
+	}
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+
+	w := depthWalker{doc: rc.Doc, fragments: map[string]int{}, visiting: map[string]bool{}}
+	depth := w.selectionDepth(op.SelectionSet)
+	if depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// depthWalker measures the depth of the selections of a document.
+type depthWalker struct {
+	doc *ast.QueryDocument
+	// fragments caches the depth of the fragments measured so far, so that
+	// a fragment spread many times is walked once.
+	fragments map[string]int
+	// visiting guards against fragments spreading themselves.
+	visiting map[string]bool
+}
+
+// selectionDepth returns the number of nested fields of the deepest path
+// in set.
+func (w *depthWalker) selectionDepth(set ast.SelectionSet) int {
+	max := 0
+	for _, sel := range set {
+		var depth int
+		switch sel := sel.(type) {
+		case *ast.Field:
+			depth = 1 + w.selectionDepth(sel.SelectionSet)
+		case *ast.InlineFragment:
+			depth = w.selectionDepth(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			depth = w.fragmentDepth(sel.Name)
+		}
+		if depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
+// fragmentDepth returns the depth of the selections of the fragment name,
+// or 0 when it is unknown or spreads itself.
+func (w *depthWalker) fragmentDepth(name string) int {
+	if depth, ok := w.fragments[name]; ok {
+		return depth
+	}
+	f := w.doc.Fragments.ForName(name)
+	if f == nil || w.visiting[name] {
+		return 0
+	}
+	w.visiting[name] = true
+	depth := w.selectionDepth(f.SelectionSet)
+	delete(w.visiting, name)
+	w.fragments[name] = depth
+	return depth
+}
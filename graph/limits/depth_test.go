@@ -0,0 +1,72 @@
+package limits
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/introspection"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func depthOf(t *testing.T, query string) int {
+	t.Helper()
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := depthWalker{doc: doc, fragments: map[string]int{}, visiting: map[string]bool{}}
+	return w.selectionDepth(doc.Operations[0].SelectionSet)
+}
+
+func TestSelectionDepth(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "flat", query: `{ hero { name } }`, want: 2},
+		{name: "nested", query: `{ hero { friends { friends { name } } } }`, want: 4},
+		{name: "deepest branch", query: `{ hero { name friends { name } } reviews(episode: JEDI) { stars } }`, want: 3},
+		{name: "inline fragment", query: `{ hero { ... on Human { starships { name } } } }`, want: 3},
+		{name: "fragment spread", query: `{ hero { ...F } } fragment F on Character { friends { name } }`, want: 3},
+		{name: "nested fragments", query: `{ hero { ...F } } fragment F on Character { friends { ...G } } fragment G on Character { friends { name } }`, want: 4},
+		{name: "fragment at several depths", query: `{ hero { ...F friends { ...F } } } fragment F on Character { friends { name } }`, want: 4},
+		{name: "self spread", query: `{ hero { ...F } } fragment F on Character { friends { ...F } }`, want: 2},
+		{name: "introspection", query: `{ __schema { types { fields { type { ofType { name } } } } } }`, want: 6},
+		{name: "typename", query: `{ hero { __typename } }`, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := depthOf(t, tt.query); got != tt.want {
+				t.Errorf("depth = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIntrospectionDepth pins the depth of the playground's introspection
+// query, which the default limit of the server must leave room for.
+func TestIntrospectionDepth(t *testing.T) {
+	if got := depthOf(t, introspection.Query); got != 13 {
+		t.Errorf("depth = %d, want 12", got)
+	}
+}
+
+// TestFragmentsWalkedOnce checks that a fragment spread at every level of
+// a deep chain is measured once rather than once per spread.
+func TestFragmentsWalkedOnce(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{ hero { ...F0 } }`)
+	const n = 40
+	for i := 0; i < n; i++ {
+		// Each fragment spreads the next one twice, which takes 2^n walks
+		// without caching.
+		b.WriteString(" fragment F" + strconv.Itoa(i) + " on Character { friends { ...F" + strconv.Itoa(i+1) + " } others: friends { ...F" + strconv.Itoa(i+1) + " } }")
+	}
+	b.WriteString(" fragment F" + strconv.Itoa(n) + " on Character { name }")
+	if got, want := depthOf(t, b.String()), 2+n; got != want {
+		t.Errorf("depth = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package loader batches the lookups made while resolving a single request,
+// so a nesting level of the query costs one fetch from the store instead
+// of one fetch per node.
+package loader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWait is how long a batch collects keys before it is fetched.
+	defaultWait = time.Millisecond
+	// defaultMaxBatch is the number of keys that triggers an early fetch.
+	defaultMaxBatch = 100
+)
+
+var errMismatch = errors.New("loader: fetch returned a value count not matching the keys")
+
+// Loader coalesces the keys requested by concurrent callers within a short
+// window into a single call of its fetch function. Values are not cached
+// beyond the batch they were fetched in, so a mutation is always visible
+// to the lookups that follow it.
+type Loader[K comparable, V any] struct {
+	fetch    func(ctx context.Context, keys []K) ([]V, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys       []K
+	index      map[K]int
+	dispatched bool
+	done       chan struct{}
+
+	values []V
+	err    error
+}
+
+// New returns a loader calling fetch with the keys of each batch. fetch
+// must return one value per key, in the order of the keys.
+func New[K comparable, V any](fetch func(ctx context.Context, keys []K) ([]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     defaultWait,
+		maxBatch: defaultMaxBatch,
+	}
+}
+
+// Load returns the value of key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	values, err := l.LoadAll(ctx, []K{key})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return values[0], nil
+}
+
+// LoadAll returns the values of keys, in the same order.
+func (l *Loader[K, V]) LoadAll(ctx context.Context, keys []K) ([]V, error) {
+	type slot struct {
+		b *batch[K, V]
+		i int
+	}
+	slots := make([]slot, len(keys))
+
+	l.mu.Lock()
+	for i, k := range keys {
+		b, j := l.add(ctx, k)
+		slots[i] = slot{b, j}
+	}
+	l.mu.Unlock()
+
+	values := make([]V, len(keys))
+	for i, s := range slots {
+		select {
+		case <-s.b.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if s.b.err != nil {
+			return nil, s.b.err
+		}
+		values[i] = s.b.values[s.i]
+	}
+	return values, nil
+}
+
+// add puts key in the open batch, opening one if needed, and returns the
+// batch with the position of key in it. l.mu must be held.
+//
+// A batch serves every caller that joined it, so it is fetched with the
+// values of ctx but not its cancellation: a caller giving up only stops
+// waiting for the batch, as LoadAll does.
+func (l *Loader[K, V]) add(ctx context.Context, key K) (*batch[K, V], int) {
+	ctx = context.WithoutCancel(ctx)
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{
+			index: map[K]int{},
+			done:  make(chan struct{}),
+		}
+		l.batch = b
+		time.AfterFunc(l.wait, func() {
+			l.mu.Lock()
+			ok := l.close(b)
+			l.mu.Unlock()
+			if ok {
+				l.run(ctx, b)
+			}
+		})
+	}
+
+	if i, ok := b.index[key]; ok {
+		return b, i
+	}
+	i := len(b.keys)
+	b.keys = append(b.keys, key)
+	b.index[key] = i
+	if len(b.keys) >= l.maxBatch && l.close(b) {
+		go l.run(ctx, b)
+	}
+	return b, i
+}
+
+// close stops b from accepting keys and reports whether the caller is the
+// one who must run it. l.mu must be held.
+func (l *Loader[K, V]) close(b *batch[K, V]) bool {
+	if b.dispatched {
+		return false
+	}
+	b.dispatched = true
+	if l.batch == b {
+		l.batch = nil
+	}
+	return true
+}
+
+func (l *Loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	defer close(b.done)
+
+	b.values, b.err = l.fetch(ctx, b.keys)
+	if b.err == nil && len(b.values) != len(b.keys) {
+		b.err = errMismatch
+	}
+}
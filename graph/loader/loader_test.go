@@ -0,0 +1,62 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCancelledCaller checks that a caller giving up does not fail the
+// batch it shares with other callers.
+func TestCancelledCaller(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetched [][]string
+	)
+	l := New(func(ctx context.Context, keys []string) ([]string, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		fetched = append(fetched, keys)
+		mu.Unlock()
+		return keys, nil
+	})
+	l.wait = 50 * time.Millisecond
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := l.Load(cancelled, "a")
+		errs <- err
+	}()
+	// Let the cancelled caller open the batch before the other one joins.
+	time.Sleep(10 * time.Millisecond)
+	done := make(chan struct{})
+	var (
+		v   string
+		err error
+	)
+	go func() {
+		defer close(done)
+		v, err = l.Load(context.Background(), "b")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Load() error = %v, want %v", err, context.Canceled)
+	}
+	<-done
+	if err != nil || v != "b" {
+		t.Errorf("Load() = %q, %v, want %q, nil", v, err, "b")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fetched) != 1 || !slices.Equal(fetched[0], []string{"a", "b"}) {
+		t.Errorf("fetched %v, want a single batch of a and b", fetched)
+	}
+}
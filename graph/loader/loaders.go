@@ -0,0 +1,59 @@
+package loader
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+)
+
+type ctxKey struct{}
+
+// Loaders are the loaders of a single request.
+type Loaders struct {
+	Characters     *Loader[string, model.Character]
+	Starships      *Loader[string, *model.Starship]
+	StarshipPilots *Loader[string, []string]
+	Vehicles       *Loader[string, *model.Vehicle]
+	VehiclePilots  *Loader[string, []string]
+	Films          *Loader[model.Episode, *model.Film]
+	Planets        *Loader[string, *model.Planet]
+	Residents      *Loader[string, []string]
+	Species        *Loader[string, *model.Species]
+}
+
+// NewLoaders returns loaders fetching from repo.
+func NewLoaders(repo store.Repository) *Loaders {
+	return &Loaders{
+		Characters:     New(repo.Characters.CharactersByIDs),
+		Starships:      New(repo.Starships.StarshipsByIDs),
+		StarshipPilots: New(repo.Starships.StarshipPilotIDs),
+		Vehicles:       New(repo.Vehicles.VehiclesByIDs),
+		VehiclePilots:  New(repo.Vehicles.VehiclePilotIDs),
+		Films:          New(repo.Films.FilmsByEpisodes),
+		Planets:        New(repo.Planets.PlanetsByIDs),
+		Residents:      New(repo.Planets.ResidentIDs),
+		Species:        New(repo.Species.SpeciesByIDs),
+	}
+}
+
+// Middleware gives every request its own loaders fetching from repo.
+func Middleware(repo store.Repository, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithLoaders(r.Context(), NewLoaders(repo))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WithLoaders returns a copy of ctx carrying l.
+func WithLoaders(ctx context.Context, l *Loaders) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// For returns the loaders of the request ctx belongs to, or nil if the
+// request did not go through Middleware.
+func For(ctx context.Context) *Loaders {
+	l, _ := ctx.Value(ctxKey{}).(*Loaders)
+	return l
+}
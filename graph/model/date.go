@@ -0,0 +1,29 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// DateLayout is the format of the Date scalar, a calendar day in UTC.
+const DateLayout = "2006-01-02"
+
+// MarshalDate writes the day of t, such as "1977-05-25".
+func MarshalDate(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.Format(DateLayout)))
+	})
+}
+
+// UnmarshalDate parses a day written as MarshalDate writes it.
+func UnmarshalDate(v interface{}) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%T is not a date string", v)
+	}
+	return time.Parse(DateLayout, s)
+}
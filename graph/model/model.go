@@ -1,45 +1,117 @@
 package model
 
 import (
-	"encoding/base64"
-	"fmt"
 	"time"
+
+	"github.com/MatsuoTakuro/starwars/graph/units"
 )
 
 type CharacterFields struct {
-	ID        string
-	Name      string
-	FriendIds []string
-	AppearsIn []Episode
+	ID          string
+	Name        string
+	FriendIds   []string
+	AppearsIn   []Episode
+	StarshipIds []string
+	VehicleIds  []string
 }
 
 type Human struct {
 	CharacterFields
-	StarshipIds  []string
 	HeightMeters float64
 	Mass         float64
+	// HomeworldID and SpeciesID are empty when unknown.
+	HomeworldID string
+	SpeciesID   string
 }
 
-func (h *Human) Height(unit LengthUnit) float64 {
-	switch unit {
-	case "METER", "":
-		return h.HeightMeters
-	case "FOOT":
-		return h.HeightMeters * 3.28084
-	default:
-		panic("invalid unit")
-	}
+func (h *Human) Height(unit *units.LengthUnit) (float64, error) {
+	return units.Length(h.HeightMeters, unit)
 }
 
 func (Human) IsCharacter()    {}
 func (Human) IsSearchResult() {}
 
 type Review struct {
+	// ID and Episode are set by the store when the review is added.
+	ID         string
+	Episode    Episode
 	Stars      int
 	Commentary *string
 	Time       time.Time
 }
 
+func (Review) IsSearchResult() {}
+
+type Starship struct {
+	ID   string
+	Name string
+	// Length is in meters.
+	Length float64
+	// History is the track of the ship, oldest point first.
+	History []HistoryPoint
+}
+
+func (Starship) IsSearchResult() {}
+
+// Coordinate locates a point of the galaxy map. Z is nil for the points
+// recorded in two dimensions.
+type Coordinate struct {
+	X int
+	Y int
+	Z *int
+}
+
+// HistoryPoint is a position of a starship along its track.
+type HistoryPoint struct {
+	Coordinate Coordinate
+	// Timestamp and LocationName are nil when unknown.
+	Timestamp    *time.Time
+	LocationName *string
+}
+
+type Vehicle struct {
+	ID           string
+	Name         string
+	Model        string
+	VehicleClass string
+	// Length is in meters.
+	Length float64
+}
+
+type Planet struct {
+	ID         string
+	Name       string
+	Climate    string
+	Terrain    string
+	Population *float64
+}
+
+func (Planet) IsSearchResult() {}
+
+type Species struct {
+	ID             string
+	Name           string
+	Classification string
+	Language       string
+}
+
+func (Species) IsSearchResult() {}
+
+// Film is a movie of the saga. HeroIds lists its main characters, the
+// hero of the episode first.
+type Film struct {
+	Episode       Episode
+	Title         string
+	EpisodeNumber int
+	// ReleaseDate is the day of the first theatrical release, in UTC.
+	ReleaseDate  time.Time
+	Director     string
+	OpeningCrawl string
+	HeroIds      []string
+}
+
+func (Film) IsSearchResult() {}
+
 type Droid struct {
 	CharacterFields
 	PrimaryFunction string
@@ -48,24 +120,76 @@ type Droid struct {
 func (Droid) IsCharacter()    {}
 func (Droid) IsSearchResult() {}
 
+// FriendsConnection is a page of a character's friends.
 type FriendsConnection struct {
-	Ids  []string
-	From int
-	To   int
+	Connection
+	Ids []string
+}
+
+// StarshipsConnection is a page of the starships a human has piloted.
+type StarshipsConnection struct {
+	Connection
+	Ids []string
+}
+
+// ReviewsConnection is a page of the reviews of an episode.
+type ReviewsConnection struct {
+	Connection
+	all []*Review
+}
+
+func NewReviewsConnection(reviews []*Review, page Connection) *ReviewsConnection {
+	return &ReviewsConnection{Connection: page, all: reviews}
+}
+
+func (c *ReviewsConnection) Reviews() []*Review {
+	return c.all[c.From:c.To]
 }
 
-func (f *FriendsConnection) TotalCount() int {
-	return len(f.Ids)
+func (c *ReviewsConnection) Edges() []*ReviewsEdge {
+	edges := make([]*ReviewsEdge, c.To-c.From)
+	for i := range edges {
+		edges[i] = &ReviewsEdge{
+			Cursor: EncodeCursor(c.From + i),
+			Node:   c.all[c.From+i],
+		}
+	}
+	return edges
 }
 
-func (f *FriendsConnection) PageInfo() PageInfo {
-	return PageInfo{
-		StartCursor: EncodeCursor(f.From),
-		EndCursor:   EncodeCursor(f.To - 1),
-		HasNextPage: f.To < len(f.Ids),
+// SearchHit is a search result along with where the search text matched it.
+type SearchHit struct {
+	Result     SearchResult
+	Highlights []*SearchHighlight
+}
+
+// SearchConnection is a page of search results.
+type SearchConnection struct {
+	Connection
+	all []SearchHit
+}
+
+func NewSearchConnection(hits []SearchHit, page Connection) *SearchConnection {
+	return &SearchConnection{Connection: page, all: hits}
+}
+
+func (c *SearchConnection) Results() []SearchResult {
+	results := make([]SearchResult, c.To-c.From)
+	for i := range results {
+		results[i] = c.all[c.From+i].Result
 	}
+	return results
 }
 
-func EncodeCursor(i int) string {
-	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("cursor%d", i+1)))
+func (c *SearchConnection) Edges() []*SearchEdge {
+	edges := make([]*SearchEdge, c.To-c.From)
+	for i := range edges {
+		hit := c.all[c.From+i]
+		edges[i] = &SearchEdge{
+			Cursor:     EncodeCursor(c.From + i),
+			Node:       hit.Result,
+			Highlights: hit.Highlights,
+		}
+	}
+	return edges
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
 type Character interface {
@@ -16,43 +17,141 @@ type SearchResult interface {
 	IsSearchResult()
 }
 
+type CharacterUpdateInput struct {
+	Name            *string   `json:"name"`
+	FriendIds       []string  `json:"friendIds"`
+	AppearsIn       []Episode `json:"appearsIn"`
+	Height          *float64  `json:"height"`
+	Mass            *float64  `json:"mass"`
+	StarshipIds     []string  `json:"starshipIds"`
+	VehicleIds      []string  `json:"vehicleIds"`
+	HomeworldID     *string   `json:"homeworldId"`
+	SpeciesID       *string   `json:"speciesId"`
+	PrimaryFunction *string   `json:"primaryFunction"`
+}
+
+type DroidInput struct {
+	Name            string    `json:"name"`
+	FriendIds       []string  `json:"friendIds"`
+	AppearsIn       []Episode `json:"appearsIn"`
+	PrimaryFunction *string   `json:"primaryFunction"`
+	StarshipIds     []string  `json:"starshipIds"`
+	VehicleIds      []string  `json:"vehicleIds"`
+}
+
 type FriendsEdge struct {
 	Cursor string    `json:"cursor"`
 	Node   Character `json:"node"`
 }
 
+type FriendshipIssue struct {
+	Kind        FriendshipIssueKind `json:"kind"`
+	CharacterID string              `json:"characterId"`
+	FriendID    string              `json:"friendId"`
+}
+
+type HistoryPointInput struct {
+	X            int        `json:"x"`
+	Y            int        `json:"y"`
+	Z            *int       `json:"z"`
+	Timestamp    *time.Time `json:"timestamp"`
+	LocationName *string    `json:"locationName"`
+}
+
+type HumanInput struct {
+	Name        string    `json:"name"`
+	Height      *float64  `json:"height"`
+	Mass        *float64  `json:"mass"`
+	FriendIds   []string  `json:"friendIds"`
+	AppearsIn   []Episode `json:"appearsIn"`
+	StarshipIds []string  `json:"starshipIds"`
+	VehicleIds  []string  `json:"vehicleIds"`
+	HomeworldID *string   `json:"homeworldId"`
+	SpeciesID   *string   `json:"speciesId"`
+}
+
 type PageInfo struct {
-	StartCursor string `json:"startCursor"`
-	EndCursor   string `json:"endCursor"`
-	HasNextPage bool   `json:"hasNextPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+}
+
+type ReviewsEdge struct {
+	Cursor string  `json:"cursor"`
+	Node   *Review `json:"node"`
+}
+
+type SearchEdge struct {
+	Cursor     string             `json:"cursor"`
+	Node       SearchResult       `json:"node"`
+	Highlights []*SearchHighlight `json:"highlights"`
+}
+
+type SearchHighlight struct {
+	Field   string       `json:"field"`
+	Snippet string       `json:"snippet"`
+	Matches []*TextRange `json:"matches"`
 }
 
-type Starship struct {
-	ID      string  `json:"id"`
-	Name    string  `json:"name"`
-	Length  float64 `json:"length"`
-	History [][]int `json:"history"`
+type StarshipInput struct {
+	Name    string               `json:"name"`
+	Length  float64              `json:"length"`
+	History [][]int              `json:"history"`
+	Track   []*HistoryPointInput `json:"track"`
 }
 
-func (Starship) IsSearchResult() {}
+type StarshipMovement struct {
+	Starship *Starship     `json:"starship"`
+	Position *HistoryPoint `json:"position"`
+}
+
+type StarshipUpdateInput struct {
+	Name    *string              `json:"name"`
+	Length  *float64             `json:"length"`
+	History [][]int              `json:"history"`
+	Track   []*HistoryPointInput `json:"track"`
+}
+
+type StarshipsEdge struct {
+	Cursor string    `json:"cursor"`
+	Node   *Starship `json:"node"`
+}
+
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
 
 type Episode string
 
 const (
-	EpisodeNewhope Episode = "NEWHOPE"
-	EpisodeEmpire  Episode = "EMPIRE"
-	EpisodeJedi    Episode = "JEDI"
+	EpisodeNewhope   Episode = "NEWHOPE"
+	EpisodeEmpire    Episode = "EMPIRE"
+	EpisodeJedi      Episode = "JEDI"
+	EpisodePhantom   Episode = "PHANTOM"
+	EpisodeClones    Episode = "CLONES"
+	EpisodeSith      Episode = "SITH"
+	EpisodeAwakens   Episode = "AWAKENS"
+	EpisodeLastjedi  Episode = "LASTJEDI"
+	EpisodeSkywalker Episode = "SKYWALKER"
 )
 
 var AllEpisode = []Episode{
 	EpisodeNewhope,
 	EpisodeEmpire,
 	EpisodeJedi,
+	EpisodePhantom,
+	EpisodeClones,
+	EpisodeSith,
+	EpisodeAwakens,
+	EpisodeLastjedi,
+	EpisodeSkywalker,
 }
 
 func (e Episode) IsValid() bool {
 	switch e {
-	case EpisodeNewhope, EpisodeEmpire, EpisodeJedi:
+	case EpisodeNewhope, EpisodeEmpire, EpisodeJedi, EpisodePhantom, EpisodeClones, EpisodeSith, EpisodeAwakens, EpisodeLastjedi, EpisodeSkywalker:
 		return true
 	}
 	return false
@@ -79,43 +178,94 @@ func (e Episode) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-type LengthUnit string
+type FriendshipIssueKind string
+
+const (
+	FriendshipIssueKindAsymmetric FriendshipIssueKind = "ASYMMETRIC"
+	FriendshipIssueKindDangling   FriendshipIssueKind = "DANGLING"
+)
+
+var AllFriendshipIssueKind = []FriendshipIssueKind{
+	FriendshipIssueKindAsymmetric,
+	FriendshipIssueKindDangling,
+}
+
+func (e FriendshipIssueKind) IsValid() bool {
+	switch e {
+	case FriendshipIssueKindAsymmetric, FriendshipIssueKindDangling:
+		return true
+	}
+	return false
+}
+
+func (e FriendshipIssueKind) String() string {
+	return string(e)
+}
+
+func (e *FriendshipIssueKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FriendshipIssueKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FriendshipIssueKind", str)
+	}
+	return nil
+}
+
+func (e FriendshipIssueKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SearchType string
 
 const (
-	LengthUnitMeter LengthUnit = "METER"
-	LengthUnitFoot  LengthUnit = "FOOT"
+	SearchTypeHuman    SearchType = "HUMAN"
+	SearchTypeDroid    SearchType = "DROID"
+	SearchTypeStarship SearchType = "STARSHIP"
+	SearchTypeReview   SearchType = "REVIEW"
+	SearchTypeFilm     SearchType = "FILM"
+	SearchTypePlanet   SearchType = "PLANET"
+	SearchTypeSpecies  SearchType = "SPECIES"
 )
 
-var AllLengthUnit = []LengthUnit{
-	LengthUnitMeter,
-	LengthUnitFoot,
+var AllSearchType = []SearchType{
+	SearchTypeHuman,
+	SearchTypeDroid,
+	SearchTypeStarship,
+	SearchTypeReview,
+	SearchTypeFilm,
+	SearchTypePlanet,
+	SearchTypeSpecies,
 }
 
-func (e LengthUnit) IsValid() bool {
+func (e SearchType) IsValid() bool {
 	switch e {
-	case LengthUnitMeter, LengthUnitFoot:
+	case SearchTypeHuman, SearchTypeDroid, SearchTypeStarship, SearchTypeReview, SearchTypeFilm, SearchTypePlanet, SearchTypeSpecies:
 		return true
 	}
 	return false
 }
 
-func (e LengthUnit) String() string {
+func (e SearchType) String() string {
 	return string(e)
 }
 
-func (e *LengthUnit) UnmarshalGQL(v interface{}) error {
+func (e *SearchType) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = LengthUnit(str)
+	*e = SearchType(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid LengthUnit", str)
+		return fmt.Errorf("%s is not a valid SearchType", str)
 	}
 	return nil
 }
 
-func (e LengthUnit) MarshalGQL(w io.Writer) {
+func (e SearchType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const cursorPrefix = "cursor"
+
+// ErrInvalidCursor is returned for cursors this server did not hand out.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor returns the opaque cursor of the edge at index i.
+func EncodeCursor(i int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s%d", cursorPrefix, i)))
+}
+
+// DecodeCursor returns the index of the edge a cursor points at.
+func DecodeCursor(cursor string) (int, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(b), cursorPrefix) {
+		return 0, ErrInvalidCursor
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(string(b), cursorPrefix))
+	if err != nil || i < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return i, nil
+}
+
+// Connection is the page [From, To) of a list of Count edges. It is
+// embedded in every connection type to provide totalCount and pageInfo.
+type Connection struct {
+	Count int
+	From  int
+	To    int
+}
+
+// NewConnection applies the Relay pagination arguments to a list of n
+// edges, following the Relay Cursor Connections specification: after and
+// before narrow the list first, then first keeps its head and last its tail.
+// Cursors pointing past the end of the list are rejected.
+func NewConnection(n int, first *int, after *string, last *int, before *string) (Connection, error) {
+	from, to := 0, n
+	if after != nil {
+		i, err := decodeCursorIn(*after, n)
+		if err != nil {
+			return Connection{}, err
+		}
+		from = min(i+1, n)
+	}
+	if before != nil {
+		i, err := decodeCursorIn(*before, n)
+		if err != nil {
+			return Connection{}, err
+		}
+		to = i
+	}
+	if from > to {
+		from = to
+	}
+
+	if first != nil {
+		if *first < 0 {
+			return Connection{}, errors.New("first must not be negative")
+		}
+		if *first < to-from {
+			to = from + *first
+		}
+	}
+	if last != nil {
+		if *last < 0 {
+			return Connection{}, errors.New("last must not be negative")
+		}
+		if *last < to-from {
+			from = to - *last
+		}
+	}
+	return Connection{Count: n, From: from, To: to}, nil
+}
+
+// decodeCursorIn decodes a cursor of a list of n edges. A cursor may point
+// just past the last edge, which a deletion since it was handed out can
+// cause, but no further.
+func decodeCursorIn(cursor string, n int) (int, error) {
+	i, err := DecodeCursor(cursor)
+	if err != nil {
+		return 0, err
+	}
+	if i > n {
+		return 0, fmt.Errorf("%w: it points past the %d edges of the list", ErrInvalidCursor, n)
+	}
+	return i, nil
+}
+
+func (c Connection) TotalCount() int {
+	return c.Count
+}
+
+// PageInfo describes the page. The cursors of an empty page are null.
+func (c Connection) PageInfo() PageInfo {
+	info := PageInfo{
+		HasNextPage:     c.To < c.Count,
+		HasPreviousPage: c.From > 0,
+	}
+	if c.From < c.To {
+		start, end := EncodeCursor(c.From), EncodeCursor(c.To-1)
+		info.StartCursor, info.EndCursor = &start, &end
+	}
+	return info
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNewConnection(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+	cursor := func(i int) *string { c := EncodeCursor(i); return &c }
+
+	tests := []struct {
+		name                   string
+		n                      int
+		first, last            *int
+		after, before          *string
+		from, to               int
+		hasNext, hasPrev       bool
+		startCursor, endCursor *string
+	}{
+		{name: "everything", n: 5, from: 0, to: 5, startCursor: cursor(0), endCursor: cursor(4)},
+		{name: "first", n: 5, first: ptr(2), from: 0, to: 2, hasNext: true, startCursor: cursor(0), endCursor: cursor(1)},
+		{name: "first after", n: 5, first: ptr(2), after: cursor(1), from: 2, to: 4, hasNext: true, hasPrev: true, startCursor: cursor(2), endCursor: cursor(3)},
+		{name: "first past the end", n: 5, first: ptr(10), after: cursor(2), from: 3, to: 5, hasPrev: true, startCursor: cursor(3), endCursor: cursor(4)},
+		{name: "last", n: 5, last: ptr(2), from: 3, to: 5, hasPrev: true, startCursor: cursor(3), endCursor: cursor(4)},
+		{name: "last before", n: 5, last: ptr(2), before: cursor(3), from: 1, to: 3, hasNext: true, hasPrev: true, startCursor: cursor(1), endCursor: cursor(2)},
+		{name: "last past the start", n: 5, last: ptr(10), before: cursor(2), from: 0, to: 2, hasNext: true, startCursor: cursor(0), endCursor: cursor(1)},
+		{name: "after and before", n: 5, after: cursor(0), before: cursor(4), from: 1, to: 4, hasNext: true, hasPrev: true, startCursor: cursor(1), endCursor: cursor(3)},
+		{name: "first and last", n: 5, first: ptr(4), last: ptr(2), from: 2, to: 4, hasNext: true, hasPrev: true, startCursor: cursor(2), endCursor: cursor(3)},
+		{name: "first zero", n: 5, first: ptr(0), from: 0, to: 0, hasNext: true},
+		{name: "after the last edge", n: 5, after: cursor(4), from: 5, to: 5, hasPrev: true},
+		{name: "after just past the end", n: 5, after: cursor(5), from: 5, to: 5, hasPrev: true},
+		{name: "before the first edge", n: 5, before: cursor(0), from: 0, to: 0, hasNext: true},
+		{name: "after beyond before", n: 5, after: cursor(3), before: cursor(1), from: 1, to: 1, hasNext: true, hasPrev: true},
+		{name: "empty list", n: 0, first: ptr(3), from: 0, to: 0},
+		{name: "huge first", n: 5, first: ptr(math.MaxInt), after: cursor(0), from: 1, to: 5, hasPrev: true, startCursor: cursor(1), endCursor: cursor(4)},
+		{name: "huge last", n: 5, last: ptr(math.MaxInt), before: cursor(4), from: 0, to: 4, hasNext: true, startCursor: cursor(0), endCursor: cursor(3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConnection(tt.n, tt.first, tt.after, tt.last, tt.before)
+			if err != nil {
+				t.Fatalf("NewConnection() error = %v", err)
+			}
+			if c.From != tt.from || c.To != tt.to || c.TotalCount() != tt.n {
+				t.Errorf("NewConnection() = %+v, want [%d, %d) of %d", c, tt.from, tt.to, tt.n)
+			}
+			info := c.PageInfo()
+			if info.HasNextPage != tt.hasNext || info.HasPreviousPage != tt.hasPrev {
+				t.Errorf("PageInfo() next, previous = %v, %v, want %v, %v", info.HasNextPage, info.HasPreviousPage, tt.hasNext, tt.hasPrev)
+			}
+			if !equalCursors(info.StartCursor, tt.startCursor) || !equalCursors(info.EndCursor, tt.endCursor) {
+				t.Errorf("PageInfo() cursors = %v, %v, want %v, %v", deref(info.StartCursor), deref(info.EndCursor), deref(tt.startCursor), deref(tt.endCursor))
+			}
+		})
+	}
+}
+
+func TestNewConnectionErrors(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name          string
+		n             int
+		first, last   *int
+		after, before *string
+		wantCursorErr bool
+	}{
+		{name: "negative first", n: 5, first: ptr(-1)},
+		{name: "negative last", n: 5, last: ptr(-1)},
+		{name: "malformed cursor", n: 5, after: str("not a cursor"), wantCursorErr: true},
+		{name: "foreign cursor", n: 5, after: str("Zm9vMQ=="), wantCursorErr: true},
+		{name: "negative cursor", n: 5, before: str(EncodeCursor(-1)), wantCursorErr: true},
+		{name: "after past the end", n: 5, after: str(EncodeCursor(6)), wantCursorErr: true},
+		{name: "before past the end", n: 5, before: str(EncodeCursor(6)), wantCursorErr: true},
+		{name: "after the largest index", n: 5, after: str(EncodeCursor(math.MaxInt)), wantCursorErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConnection(tt.n, tt.first, tt.after, tt.last, tt.before)
+			if err == nil {
+				t.Fatal("NewConnection() error = nil, want an error")
+			}
+			if got := errors.Is(err, ErrInvalidCursor); got != tt.wantCursorErr {
+				t.Errorf("errors.Is(%v, ErrInvalidCursor) = %v, want %v", err, got, tt.wantCursorErr)
+			}
+		})
+	}
+}
+
+func equalCursors(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
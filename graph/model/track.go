@@ -0,0 +1,124 @@
+package model
+
+import (
+	"math"
+)
+
+// BoundingBox is the smallest rectangle of the galaxy map holding a set of
+// points. Both corners are inclusive.
+type BoundingBox struct {
+	MinX int
+	MinY int
+	MaxX int
+	MaxY int
+}
+
+// BoxAround returns the smallest box holding the disc of the given radius
+// around x, y. Corners beyond the range of int are clamped to it.
+func BoxAround(x, y int, radius float64) BoundingBox {
+	return BoundingBox{
+		MinX: clampInt(math.Floor(float64(x) - radius)),
+		MinY: clampInt(math.Floor(float64(y) - radius)),
+		MaxX: clampInt(math.Ceil(float64(x) + radius)),
+		MaxY: clampInt(math.Ceil(float64(y) + radius)),
+	}
+}
+
+// clampInt converts the integral f to an int, saturating at the bounds of
+// int instead of overflowing.
+func clampInt(f float64) int {
+	switch {
+	case f <= math.MinInt:
+		return math.MinInt
+	case f >= math.MaxInt:
+		// math.MaxInt rounds up to 2^63 as a float64, which no int holds.
+		return math.MaxInt
+	default:
+		return int(f)
+	}
+}
+
+// Revisit gathers the points of a track sitting at the same coordinate.
+type Revisit struct {
+	Coordinate Coordinate
+	// Visits holds the points at Coordinate, in the order of the track.
+	Visits []*HistoryPoint
+}
+
+// Distance returns the length of the track of s in map units, going
+// straight from each point to the next one. The height of two points is
+// only accounted for when both have one. Differences are taken as floats,
+// so that coordinates far apart cannot overflow.
+func (s *Starship) Distance() float64 {
+	var d float64
+	for i := 1; i < len(s.History); i++ {
+		a, b := s.History[i-1].Coordinate, s.History[i].Coordinate
+		dx, dy := float64(b.X)-float64(a.X), float64(b.Y)-float64(a.Y)
+		var dz float64
+		if a.Z != nil && b.Z != nil {
+			dz = float64(*b.Z) - float64(*a.Z)
+		}
+		d += math.Sqrt(dx*dx + dy*dy + dz*dz)
+	}
+	return d
+}
+
+// BoundingBox returns the box holding every point of the track of s on the
+// map plane, or nil when the track is empty.
+func (s *Starship) BoundingBox() *BoundingBox {
+	if len(s.History) == 0 {
+		return nil
+	}
+	first := s.History[0].Coordinate
+	b := &BoundingBox{MinX: first.X, MinY: first.Y, MaxX: first.X, MaxY: first.Y}
+	for _, p := range s.History[1:] {
+		b.MinX = min(b.MinX, p.Coordinate.X)
+		b.MinY = min(b.MinY, p.Coordinate.Y)
+		b.MaxX = max(b.MaxX, p.Coordinate.X)
+		b.MaxY = max(b.MaxY, p.Coordinate.Y)
+	}
+	return b
+}
+
+// LastKnownPosition returns the latest point of the track of s, or nil
+// when the track is empty.
+func (s *Starship) LastKnownPosition() *HistoryPoint {
+	if len(s.History) == 0 {
+		return nil
+	}
+	return &s.History[len(s.History)-1]
+}
+
+// Revisits returns the coordinates the track of s goes through more than
+// once, ordered by first visit. Coordinates only match when they agree on
+// their height, or both lack one.
+func (s *Starship) Revisits() []*Revisit {
+	type key struct {
+		x, y, z int
+		hasZ    bool
+	}
+	byKey := map[key]*Revisit{}
+	var order []*Revisit
+	for i := range s.History {
+		p := &s.History[i]
+		k := key{x: p.Coordinate.X, y: p.Coordinate.Y}
+		if p.Coordinate.Z != nil {
+			k.z, k.hasZ = *p.Coordinate.Z, true
+		}
+		r, ok := byKey[k]
+		if !ok {
+			r = &Revisit{Coordinate: p.Coordinate}
+			byKey[k] = r
+			order = append(order, r)
+		}
+		r.Visits = append(r.Visits, p)
+	}
+
+	l := []*Revisit{}
+	for _, r := range order {
+		if len(r.Visits) > 1 {
+			l = append(l, r)
+		}
+	}
+	return l
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	z := func(i int) *int { return &i }
+	at := func(x, y int, z *int) HistoryPoint {
+		return HistoryPoint{Coordinate: Coordinate{X: x, Y: y, Z: z}}
+	}
+
+	tests := []struct {
+		name    string
+		history []HistoryPoint
+		want    float64
+	}{
+		{name: "empty", want: 0},
+		{name: "single point", history: []HistoryPoint{at(1, 2, nil)}, want: 0},
+		{name: "plane", history: []HistoryPoint{at(0, 0, nil), at(3, 4, nil), at(3, 0, nil)}, want: 9},
+		{name: "height", history: []HistoryPoint{at(0, 0, z(0)), at(2, 3, z(6))}, want: 7},
+		{name: "height on one side only", history: []HistoryPoint{at(0, 0, z(100)), at(3, 4, nil)}, want: 5},
+		{name: "extreme heights", history: []HistoryPoint{at(0, 0, z(math.MaxInt64)), at(0, 0, z(-math.MaxInt64))}, want: 2 * math.MaxInt64},
+		{name: "extreme plane", history: []HistoryPoint{at(math.MinInt64, 0, nil), at(math.MaxInt64, 0, nil)}, want: 2 * math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Starship{History: tt.history}
+			if got := s.Distance(); math.Abs(got-tt.want) > 1e-9*math.Max(1, tt.want) {
+				t.Errorf("Distance() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package pubsub fans events out to the GraphQL subscriptions listening
+// for them.
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// bufferSize is the number of events a subscriber may lag behind before
+// further events are dropped for it.
+const bufferSize = 16
+
+// Broker delivers values published on a topic to every live subscriber of
+// that topic. The zero value is ready to use.
+type Broker[T any] struct {
+	mu   sync.Mutex
+	subs map[string]map[chan T]struct{}
+}
+
+// Subscribe returns a channel receiving the values published on topic, or
+// on any topic when topic is empty. The channel is closed once ctx is done.
+func (b *Broker[T]) Subscribe(ctx context.Context, topic string) <-chan T {
+	ch := make(chan T, bufferSize)
+
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = map[string]map[chan T]struct{}{}
+	}
+	if b.subs[topic] == nil {
+		b.subs[topic] = map[chan T]struct{}{}
+	}
+	b.subs[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[topic], ch)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// Publish sends v to the subscribers of topic and to the ones listening on
+// every topic. It never blocks: a subscriber whose buffer is full misses v.
+func (b *Broker[T]) Publish(topic string, v T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[topic] {
+		send(ch, v)
+	}
+	if topic != "" {
+		for ch := range b.subs[""] {
+			send(ch, v)
+		}
+	}
+}
+
+// Subscribers returns the number of live subscriptions to topic.
+func (b *Broker[T]) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[topic])
+}
+
+func send[T any](ch chan T, v T) {
+	select {
+	case ch <- v:
+	default:
+	}
+}
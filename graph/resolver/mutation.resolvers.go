@@ -5,19 +5,287 @@ package resolver
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/MatsuoTakuro/starwars/graph/generated"
 	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/search"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+	"github.com/MatsuoTakuro/starwars/graph/validation"
 )
 
 func (r *mutationResolver) CreateReview(ctx context.Context, episode model.Episode, review model.Review) (*model.Review, error) {
-	review.Time = time.Now()
-	time.Sleep(1 * time.Second)
-	r.reviews[episode] = append(r.reviews[episode], &review)
+	now := time.Now()
+	if err := validation.Join(ctx, validateReview(review, now)); err != nil {
+		return nil, err
+	}
+	if review.Time.IsZero() {
+		review.Time = now
+	}
+	if err := r.simulateLatency(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.reviews.AddReview(ctx, episode, &review); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) { ix.Put(reviewDocument(&review)) })
+	r.reviewAdded.Publish(episode.String(), &review)
 	return &review, nil
 }
 
+func (r *mutationResolver) CreateHuman(ctx context.Context, input model.HumanInput) (*model.Human, error) {
+	h := &model.Human{
+		CharacterFields: model.CharacterFields{
+			Name:        input.Name,
+			FriendIds:   input.FriendIds,
+			AppearsIn:   input.AppearsIn,
+			StarshipIds: input.StarshipIds,
+			VehicleIds:  input.VehicleIds,
+		},
+	}
+	if input.Height != nil {
+		h.HeightMeters = *input.Height
+	}
+	if input.Mass != nil {
+		h.Mass = *input.Mass
+	}
+	if err := checkUniqueIDs(h.CharacterFields, "input"); err != nil {
+		return nil, err
+	}
+	if err := r.checkCharacterIDs(ctx, h.FriendIds); err != nil {
+		return nil, err
+	}
+	if err := r.checkStarshipIDs(ctx, h.StarshipIds); err != nil {
+		return nil, err
+	}
+	if err := r.checkVehicleIDs(ctx, h.VehicleIds); err != nil {
+		return nil, err
+	}
+	if err := r.checkHumanRefs(ctx, input.HomeworldID, input.SpeciesID); err != nil {
+		return nil, err
+	}
+	if input.HomeworldID != nil {
+		h.HomeworldID = *input.HomeworldID
+	}
+	if input.SpeciesID != nil {
+		h.SpeciesID = *input.SpeciesID
+	}
+	if err := r.characters.CreateHuman(ctx, h); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) { ix.Put(humanDocument(h)) })
+	return h, nil
+}
+
+func (r *mutationResolver) CreateDroid(ctx context.Context, input model.DroidInput) (*model.Droid, error) {
+	d := &model.Droid{
+		CharacterFields: model.CharacterFields{
+			Name:        input.Name,
+			FriendIds:   input.FriendIds,
+			AppearsIn:   input.AppearsIn,
+			StarshipIds: input.StarshipIds,
+			VehicleIds:  input.VehicleIds,
+		},
+	}
+	if input.PrimaryFunction != nil {
+		d.PrimaryFunction = *input.PrimaryFunction
+	}
+	if err := checkUniqueIDs(d.CharacterFields, "input"); err != nil {
+		return nil, err
+	}
+	if err := r.checkCharacterIDs(ctx, d.FriendIds); err != nil {
+		return nil, err
+	}
+	if err := r.checkStarshipIDs(ctx, d.StarshipIds); err != nil {
+		return nil, err
+	}
+	if err := r.checkVehicleIDs(ctx, d.VehicleIds); err != nil {
+		return nil, err
+	}
+	if err := r.characters.CreateDroid(ctx, d); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) { ix.Put(droidDocument(d)) })
+	return d, nil
+}
+
+func (r *mutationResolver) UpdateCharacter(ctx context.Context, id string, input model.CharacterUpdateInput) (model.Character, error) {
+	c, err := r.Query().Character(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := c.(type) {
+	case *model.Human:
+		if input.PrimaryFunction != nil {
+			return nil, fmt.Errorf("character %s is a human and has no primaryFunction", id)
+		}
+		if err := r.applyCharacterUpdate(ctx, &c.CharacterFields, input); err != nil {
+			return nil, err
+		}
+		if input.Height != nil {
+			c.HeightMeters = *input.Height
+		}
+		if input.Mass != nil {
+			c.Mass = *input.Mass
+		}
+		if err := r.checkHumanRefs(ctx, input.HomeworldID, input.SpeciesID); err != nil {
+			return nil, err
+		}
+		if input.HomeworldID != nil {
+			c.HomeworldID = *input.HomeworldID
+		}
+		if input.SpeciesID != nil {
+			c.SpeciesID = *input.SpeciesID
+		}
+		if err := r.characters.UpdateHuman(ctx, c); err != nil {
+			return nil, err
+		}
+		r.updateIndex(func(ix *search.Index) { ix.Put(humanDocument(c)) })
+		return c, nil
+	case *model.Droid:
+		if input.Height != nil || input.Mass != nil || input.HomeworldID != nil || input.SpeciesID != nil {
+			return nil, fmt.Errorf("character %s is a droid and has no height, mass, homeworld or species", id)
+		}
+		if err := r.applyCharacterUpdate(ctx, &c.CharacterFields, input); err != nil {
+			return nil, err
+		}
+		if input.PrimaryFunction != nil {
+			c.PrimaryFunction = *input.PrimaryFunction
+		}
+		if err := r.characters.UpdateDroid(ctx, c); err != nil {
+			return nil, err
+		}
+		r.updateIndex(func(ix *search.Index) { ix.Put(droidDocument(c)) })
+		return c, nil
+	default:
+		return nil, fmt.Errorf("character %s: %w", id, store.ErrNotFound)
+	}
+}
+
+func (r *mutationResolver) DeleteCharacter(ctx context.Context, id string) (model.Character, error) {
+	c, err := r.Query().Character(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("character %s: %w", id, store.ErrNotFound)
+	}
+	if err := r.characters.DeleteCharacter(ctx, id); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) {
+		ix.Remove(search.Key{Kind: model.SearchTypeHuman.String(), ID: id})
+		ix.Remove(search.Key{Kind: model.SearchTypeDroid.String(), ID: id})
+	})
+	return c, nil
+}
+
+func (r *mutationResolver) AddFriend(ctx context.Context, a string, b string) ([]model.Character, error) {
+	return r.updateFriendship(ctx, a, b, r.characters.AddFriendship)
+}
+
+func (r *mutationResolver) RemoveFriend(ctx context.Context, a string, b string) ([]model.Character, error) {
+	return r.updateFriendship(ctx, a, b, r.characters.RemoveFriendship)
+}
+
+func (r *mutationResolver) CreateStarship(ctx context.Context, input model.StarshipInput) (*model.Starship, error) {
+	history, err := starshipTrack(input.History, input.Track)
+	if err != nil {
+		return nil, err
+	}
+	s := &model.Starship{
+		Name:    input.Name,
+		Length:  input.Length,
+		History: history,
+	}
+	if s.History == nil {
+		s.History = []model.HistoryPoint{}
+	}
+	if err := r.starships.CreateStarship(ctx, s); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) { ix.Put(starshipDocument(s)) })
+	return s, nil
+}
+
+func (r *mutationResolver) UpdateStarship(ctx context.Context, id string, input model.StarshipUpdateInput) (*model.Starship, error) {
+	s, err := r.starships.Starship(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("starship %s: %w", id, store.ErrNotFound)
+	}
+
+	if input.Name != nil {
+		s.Name = *input.Name
+	}
+	if input.Length != nil {
+		s.Length = *input.Length
+	}
+	history, err := starshipTrack(input.History, input.Track)
+	if err != nil {
+		return nil, err
+	}
+	if history != nil {
+		s.History = history
+	}
+	if err := r.starships.UpdateStarship(ctx, s); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) { ix.Put(starshipDocument(s)) })
+	return s, nil
+}
+
+func (r *mutationResolver) DeleteStarship(ctx context.Context, id string) (*model.Starship, error) {
+	s, err := r.starships.Starship(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("starship %s: %w", id, store.ErrNotFound)
+	}
+	if err := r.starships.DeleteStarship(ctx, id); err != nil {
+		return nil, err
+	}
+	r.updateIndex(func(ix *search.Index) {
+		ix.Remove(search.Key{Kind: model.SearchTypeStarship.String(), ID: id})
+	})
+	return s, nil
+}
+
+func (r *mutationResolver) RecordStarshipPosition(ctx context.Context, id string, x int, y int, z *int, locationName *string) (*model.StarshipMovement, error) {
+	if err := checkCoordinate(x, y); err != nil {
+		return nil, err
+	}
+	s, err := r.starships.Starship(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("starship %s: %w", id, store.ErrNotFound)
+	}
+
+	now := time.Now()
+	p := model.HistoryPoint{
+		Coordinate:   model.Coordinate{X: x, Y: y, Z: z},
+		Timestamp:    &now,
+		LocationName: locationName,
+	}
+	if err := r.starships.AppendHistory(ctx, id, p); err != nil {
+		return nil, err
+	}
+	// The track may share its array with the store, which must not see p
+	// written past its end.
+	s.History = append(slices.Clip(s.History), p)
+	m := &model.StarshipMovement{Starship: s, Position: &s.History[len(s.History)-1]}
+	r.starshipMoved.Publish(id, m)
+	return m, nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
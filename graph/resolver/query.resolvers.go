@@ -5,7 +5,7 @@ package resolver
 
 import (
 	"context"
-	"strings"
+	"fmt"
 	"time"
 
 	"github.com/MatsuoTakuro/starwars/graph/generated"
@@ -13,75 +13,140 @@ import (
 )
 
 func (r *queryResolver) Hero(ctx context.Context, episode *model.Episode) (model.Character, error) {
-	if *episode == model.EpisodeEmpire {
-		return r.humans["1000"], nil
+	heroes, err := r.heroes(ctx, episode)
+	if err != nil || len(heroes) == 0 {
+		return nil, err
 	}
-	return r.droid["2001"], nil
+	return heroes[0], nil
+}
+
+func (r *queryResolver) Heroes(ctx context.Context, episode *model.Episode) ([]model.Character, error) {
+	return r.heroes(ctx, episode)
 }
 
 func (r *queryResolver) Reviews(ctx context.Context, episode model.Episode, since *time.Time) ([]*model.Review, error) {
-	if since == nil {
-		return r.reviews[episode], nil
-	}
+	return r.resolveReviews(ctx, episode, since)
+}
 
-	var filtered []*model.Review
-	for _, rev := range r.reviews[episode] {
-		if rev.Time.After(*since) {
-			filtered = append(filtered, rev)
-		}
+func (r *queryResolver) ReviewsConnection(ctx context.Context, episode model.Episode, since *time.Time, first *int, after *string, last *int, before *string) (*model.ReviewsConnection, error) {
+	reviews, err := r.resolveReviews(ctx, episode, since)
+	if err != nil {
+		return nil, err
+	}
+	page, err := model.NewConnection(len(reviews), first, after, last, before)
+	if err != nil {
+		return nil, err
 	}
-	return filtered, nil
+	return model.NewReviewsConnection(reviews, page), nil
 }
 
-func (r *queryResolver) Search(ctx context.Context, text string) ([]model.SearchResult, error) {
-	var l []model.SearchResult
-	for _, h := range r.humans {
-		if strings.Contains(h.Name, text) {
-			l = append(l, h)
-		}
+func (r *queryResolver) Search(ctx context.Context, text string, types []model.SearchType) ([]model.SearchResult, error) {
+	hits, err := r.search(ctx, text, types)
+	if err != nil {
+		return nil, err
 	}
-	for _, d := range r.droid {
-		if strings.Contains(d.Name, text) {
-			l = append(l, d)
-		}
+	results := make([]model.SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = hit.Result
 	}
-	for _, s := range r.starships {
-		if strings.Contains(s.Name, text) {
-			l = append(l, s)
-		}
+	return results, nil
+}
+
+func (r *queryResolver) SearchConnection(ctx context.Context, text string, types []model.SearchType, first *int, after *string, last *int, before *string) (*model.SearchConnection, error) {
+	hits, err := r.search(ctx, text, types)
+	if err != nil {
+		return nil, err
+	}
+	page, err := model.NewConnection(len(hits), first, after, last, before)
+	if err != nil {
+		return nil, err
 	}
-	return l, nil
+	return model.NewSearchConnection(hits, page), nil
+}
+
+func (r *queryResolver) Film(ctx context.Context, episode model.Episode) (*model.Film, error) {
+	return r.films.Film(ctx, episode)
+}
+
+func (r *queryResolver) Films(ctx context.Context) ([]*model.Film, error) {
+	return r.films.Films(ctx)
+}
+
+func (r *queryResolver) Planet(ctx context.Context, id string) (*model.Planet, error) {
+	return r.planets.Planet(ctx, id)
+}
+
+func (r *queryResolver) Planets(ctx context.Context) ([]*model.Planet, error) {
+	return r.planets.Planets(ctx)
 }
 
 func (r *queryResolver) Character(ctx context.Context, id string) (model.Character, error) {
-	if h, ok := r.humans[id]; ok {
-		return &h, nil
+	h, err := r.characters.Human(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if h != nil {
+		return h, nil
 	}
-	if d, ok := r.droid[id]; ok {
-		return &d, nil
+	d, err := r.characters.Droid(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d != nil {
+		return d, nil
 	}
 	return nil, nil
 }
 
 func (r *queryResolver) Droid(ctx context.Context, id string) (*model.Droid, error) {
-	if d, ok := r.droid[id]; ok {
-		return &d, nil
-	}
-	return nil, nil
+	return r.characters.Droid(ctx, id)
 }
 
 func (r *queryResolver) Human(ctx context.Context, id string) (*model.Human, error) {
-	if h, ok := r.humans[id]; ok {
-		return &h, nil
-	}
-	return nil, nil
+	return r.characters.Human(ctx, id)
 }
 
 func (r *queryResolver) Starship(ctx context.Context, id string) (*model.Starship, error) {
-	if s, ok := r.starships[id]; ok {
-		return &s, nil
+	return r.starships.Starship(ctx, id)
+}
+
+func (r *queryResolver) StarshipsNear(ctx context.Context, x int, y int, radius float64) ([]*model.Starship, error) {
+	return r.starships.StarshipsNear(ctx, x, y, radius)
+}
+
+func (r *queryResolver) StarshipsInRegion(ctx context.Context, box model.BoundingBox) ([]*model.Starship, error) {
+	if box.MinX > box.MaxX || box.MinY > box.MaxY {
+		return nil, fmt.Errorf("box corners are swapped: min (%d, %d), max (%d, %d)", box.MinX, box.MinY, box.MaxX, box.MaxY)
 	}
-	return nil, nil
+	return r.starships.StarshipsInRegion(ctx, box)
+}
+
+func (r *queryResolver) Vehicle(ctx context.Context, id string) (*model.Vehicle, error) {
+	return r.vehicles.Vehicle(ctx, id)
+}
+
+func (r *queryResolver) Vehicles(ctx context.Context) ([]*model.Vehicle, error) {
+	return r.vehicles.Vehicles(ctx)
+}
+
+func (r *queryResolver) FriendshipIssues(ctx context.Context) ([]*model.FriendshipIssue, error) {
+	humans, err := r.characters.Humans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	droids, err := r.characters.Droids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var chars []model.CharacterFields
+	for _, h := range humans {
+		chars = append(chars, h.CharacterFields)
+	}
+	for _, d := range droids {
+		chars = append(chars, d.CharacterFields)
+	}
+	return friendshipIssues(chars), nil
 }
 
 // Query returns generated.QueryResolver implementation.
@@ -5,191 +5,484 @@ package resolver
 
 import (
 	"context"
-	"encoding/base64"
-	"strconv"
-	"strings"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/MatsuoTakuro/starwars/graph/generated"
+	"github.com/MatsuoTakuro/starwars/graph/limits"
+	"github.com/MatsuoTakuro/starwars/graph/loader"
 	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/pubsub"
+	"github.com/MatsuoTakuro/starwars/graph/search"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+	"github.com/MatsuoTakuro/starwars/graph/validation"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 type Resolver struct {
-	humans    map[string]model.Human
-	droid     map[string]model.Droid
-	starships map[string]model.Starship
-	reviews   map[model.Episode][]*model.Review
+	characters store.CharacterRepository
+	starships  store.StarshipRepository
+	vehicles   store.VehicleRepository
+	reviews    store.ReviewRepository
+	films      store.FilmRepository
+	planets    store.PlanetRepository
+	species    store.SpeciesRepository
+
+	// reviewAdded publishes created reviews on their episode's topic.
+	reviewAdded pubsub.Broker[*model.Review]
+	// starshipMoved publishes recorded positions on their starship's topic.
+	starshipMoved pubsub.Broker[*model.StarshipMovement]
+
+	// index is the search index, built on the first search and kept up to
+	// date by the mutations afterwards.
+	indexMu sync.Mutex
+	index   *search.Index
+
+	// latency is added to review creation to mimic a slow backend.
+	latency time.Duration
+}
+
+// Option customises a Resolver built by NewResolver.
+type Option func(*Resolver)
+
+// WithLatency makes createReview wait for d before storing the review.
+func WithLatency(d time.Duration) Option {
+	return func(r *Resolver) {
+		r.latency = d
+	}
 }
 
+// simulateLatency blocks for the configured latency or until ctx is done.
+func (r *Resolver) simulateLatency(ctx context.Context) error {
+	if r.latency <= 0 {
+		return nil
+	}
+	t := time.NewTimer(r.latency)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loaders returns the loaders of the request, or fresh ones when the
+// request did not go through loader.Middleware.
+func (r *Resolver) loaders(ctx context.Context) *loader.Loaders {
+	if l := loader.For(ctx); l != nil {
+		return l
+	}
+	return loader.NewLoaders(store.Repository{
+		Characters: r.characters,
+		Starships:  r.starships,
+		Vehicles:   r.vehicles,
+		Reviews:    r.reviews,
+		Films:      r.films,
+		Planets:    r.planets,
+		Species:    r.species,
+	})
+}
+
+// resolveCharacters looks up every character of ids in a batch, keeping a
+// nil entry for the unknown ones.
 func (r *Resolver) resolveCharacters(ctx context.Context, ids []string) ([]model.Character, error) {
-	result := make([]model.Character, len(ids))
-	for i, id := range ids {
-		char, err := r.Query().Character(ctx, id)
-		if err != nil {
-			return nil, err
+	return r.loaders(ctx).Characters.LoadAll(ctx, ids)
+}
+
+// heroes returns the heroes of episode, the hero first. A null episode
+// stands for the default one, A New Hope.
+func (r *Resolver) heroes(ctx context.Context, episode *model.Episode) ([]model.Character, error) {
+	e := model.EpisodeNewhope
+	if episode != nil {
+		e = *episode
+	}
+	f, err := r.films.Film(ctx, e)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	return r.resolveCharacters(ctx, f.HeroIds)
+}
+
+// checkCharacterIDs fails unless every ID refers to an existing character.
+func (r *Resolver) checkCharacterIDs(ctx context.Context, ids []string) error {
+	chars, err := r.resolveCharacters(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, c := range chars {
+		if c == nil {
+			return fmt.Errorf("character %s: %w", ids[i], store.ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// checkStarshipIDs fails unless every ID refers to an existing starship.
+func (r *Resolver) checkStarshipIDs(ctx context.Context, ids []string) error {
+	starships, err := r.resolveStarships(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, s := range starships {
+		if s == nil {
+			return fmt.Errorf("starship %s: %w", ids[i], store.ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// checkVehicleIDs fails unless every ID refers to an existing vehicle.
+func (r *Resolver) checkVehicleIDs(ctx context.Context, ids []string) error {
+	vehicles, err := r.resolveVehicles(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, v := range vehicles {
+		if v == nil {
+			return fmt.Errorf("vehicle %s: %w", ids[i], store.ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// checkUniqueIDs fails with a validation error when the friends, starships
+// or vehicles of c, given by the input object at path, list an ID twice.
+func checkUniqueIDs(c model.CharacterFields, path string) error {
+	for _, f := range []struct {
+		name string
+		ids  []string
+	}{
+		{"friendIds", c.FriendIds},
+		{"starshipIds", c.StarshipIds},
+		{"vehicleIds", c.VehicleIds},
+	} {
+		seen := make(map[string]bool, len(f.ids))
+		for _, id := range f.ids {
+			if seen[id] {
+				return validation.Errorf(path+"."+f.name, "%s lists %s more than once", f.name, id)
+			}
+			seen[id] = true
 		}
-		result[i] = char
 	}
-	return result, nil
+	return nil
 }
 
-func (r *Resolver) resolveFriendConnection(_ context.Context, ids []string, first *int, after *string) (*model.FriendsConnection, error) {
-	from := 0
-	if after != nil {
-		b, err := base64.StdEncoding.DecodeString(*after)
+// checkHumanRefs fails unless the planet and species IDs, when not null,
+// refer to an existing planet and species.
+func (r *Resolver) checkHumanRefs(ctx context.Context, homeworldID, speciesID *string) error {
+	if homeworldID != nil {
+		p, err := r.loaders(ctx).Planets.Load(ctx, *homeworldID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("planet %s: %w", *homeworldID, store.ErrNotFound)
 		}
-		i, err := strconv.Atoi(strings.TrimPrefix(string(b), "cursor"))
+	}
+	if speciesID != nil {
+		sp, err := r.loaders(ctx).Species.Load(ctx, *speciesID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if sp == nil {
+			return fmt.Errorf("species %s: %w", *speciesID, store.ErrNotFound)
 		}
-		from = i
 	}
+	return nil
+}
+
+// applyCharacterUpdate copies the fields shared by every character from
+// input to c, checking the new friends, starships and vehicles exist.
+func (r *Resolver) applyCharacterUpdate(ctx context.Context, c *model.CharacterFields, input model.CharacterUpdateInput) error {
+	if input.Name != nil {
+		c.Name = *input.Name
+	}
+	if input.AppearsIn != nil {
+		c.AppearsIn = input.AppearsIn
+	}
+	if err := checkUniqueIDs(model.CharacterFields{
+		FriendIds:   input.FriendIds,
+		StarshipIds: input.StarshipIds,
+		VehicleIds:  input.VehicleIds,
+	}, "input"); err != nil {
+		return err
+	}
+	if input.FriendIds != nil {
+		for _, id := range input.FriendIds {
+			if id == c.ID {
+				return fmt.Errorf("character %s cannot be its own friend", id)
+			}
+		}
+		if err := r.checkCharacterIDs(ctx, input.FriendIds); err != nil {
+			return err
+		}
+		c.FriendIds = input.FriendIds
+	}
+	if input.StarshipIds != nil {
+		if err := r.checkStarshipIDs(ctx, input.StarshipIds); err != nil {
+			return err
+		}
+		c.StarshipIds = input.StarshipIds
+	}
+	if input.VehicleIds != nil {
+		if err := r.checkVehicleIDs(ctx, input.VehicleIds); err != nil {
+			return err
+		}
+		c.VehicleIds = input.VehicleIds
+	}
+	return nil
+}
+
+// updateFriendship checks a and b can be friends and applies update to
+// them, returning both characters as they are afterwards.
+func (r *Resolver) updateFriendship(ctx context.Context, a, b string, update func(ctx context.Context, a, b string) error) ([]model.Character, error) {
+	if a == b {
+		return nil, fmt.Errorf("character %s cannot be its own friend", a)
+	}
+	if err := r.checkCharacterIDs(ctx, []string{a, b}); err != nil {
+		return nil, err
+	}
+	if err := update(ctx, a, b); err != nil {
+		return nil, err
+	}
+	return r.resolveCharacters(ctx, []string{a, b})
+}
+
+// friendshipIssues reports every friend reference among chars that points
+// to an unknown character or is not listed back by its target.
+func friendshipIssues(chars []model.CharacterFields) []*model.FriendshipIssue {
+	sort.Slice(chars, func(i, j int) bool { return chars[i].ID < chars[j].ID })
 
-	to := len(ids)
-	if first != nil {
-		to = from + *first
-		if to > len(ids) {
-			to = len(ids)
+	friends := make(map[string]map[string]bool, len(chars))
+	for _, c := range chars {
+		friends[c.ID] = map[string]bool{}
+		for _, id := range c.FriendIds {
+			friends[c.ID][id] = true
 		}
 	}
 
+	issues := []*model.FriendshipIssue{}
+	for _, c := range chars {
+		for _, id := range c.FriendIds {
+			back, ok := friends[id]
+			switch {
+			case !ok:
+				issues = append(issues, &model.FriendshipIssue{
+					Kind:        model.FriendshipIssueKindDangling,
+					CharacterID: c.ID,
+					FriendID:    id,
+				})
+			case !back[c.ID]:
+				issues = append(issues, &model.FriendshipIssue{
+					Kind:        model.FriendshipIssueKindAsymmetric,
+					CharacterID: c.ID,
+					FriendID:    id,
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// validateReview lists what is wrong with a review about to be posted at now.
+// The bounds of stars and commentary are enforced by schema directives.
+func validateReview(review model.Review, now time.Time) []*gqlerror.Error {
+	var errs []*gqlerror.Error
+	if review.Time.After(now) {
+		errs = append(errs, validation.Errorf("review.time",
+			"time must not be in the future"))
+	}
+	return errs
+}
+
+// checkCoordinate fails unless x and y fit on the galaxy map, whose axes
+// span the 32 bits of the GraphQL Int type. The spatial index of the
+// SQLite store would truncate larger values.
+func checkCoordinate(x, y int) error {
+	for _, v := range []int{x, y} {
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return fmt.Errorf("coordinate %d is off the map, which spans %d to %d", v, math.MinInt32, math.MaxInt32)
+		}
+	}
+	return nil
+}
+
+// starshipTrack builds the track of a starship from either the raw x, y
+// pairs of history or the points of track, which cannot both be set. It
+// returns nil when neither is.
+func starshipTrack(history [][]int, track []*model.HistoryPointInput) ([]model.HistoryPoint, error) {
+	switch {
+	case history != nil && track != nil:
+		return nil, errors.New("history and track cannot both be set")
+	case history != nil:
+		l := make([]model.HistoryPoint, len(history))
+		for i, p := range history {
+			if len(p) != 2 {
+				return nil, fmt.Errorf("history point %d has %d coordinates, want 2", i, len(p))
+			}
+			if err := checkCoordinate(p[0], p[1]); err != nil {
+				return nil, fmt.Errorf("history point %d: %w", i, err)
+			}
+			l[i].Coordinate = model.Coordinate{X: p[0], Y: p[1]}
+		}
+		return l, nil
+	case track != nil:
+		l := make([]model.HistoryPoint, len(track))
+		var last *time.Time
+		for i, p := range track {
+			if err := checkCoordinate(p.X, p.Y); err != nil {
+				return nil, fmt.Errorf("track point %d: %w", i, err)
+			}
+			if p.Timestamp != nil {
+				if last != nil && p.Timestamp.Before(*last) {
+					return nil, fmt.Errorf("track point %d is older than a point before it", i)
+				}
+				last = p.Timestamp
+			}
+			l[i] = model.HistoryPoint{
+				Coordinate:   model.Coordinate{X: p.X, Y: p.Y, Z: p.Z},
+				Timestamp:    p.Timestamp,
+				LocationName: p.LocationName,
+			}
+		}
+		return l, nil
+	default:
+		return nil, nil
+	}
+}
+
+// trackBetween returns the points of history recorded after since and no
+// later than until. Either bound may be nil; the points of unknown time
+// only pass when both are.
+func trackBetween(history []model.HistoryPoint, since, until *time.Time) []*model.HistoryPoint {
+	l := make([]*model.HistoryPoint, 0, len(history))
+	for i := range history {
+		p := &history[i]
+		if since != nil || until != nil {
+			if p.Timestamp == nil ||
+				since != nil && !p.Timestamp.After(*since) ||
+				until != nil && p.Timestamp.After(*until) {
+				continue
+			}
+		}
+		l = append(l, p)
+	}
+	return l
+}
+
+// resolveStarships looks up every starship of ids in a batch, keeping a
+// nil entry for the unknown ones.
+func (r *Resolver) resolveStarships(ctx context.Context, ids []string) ([]*model.Starship, error) {
+	return r.loaders(ctx).Starships.LoadAll(ctx, ids)
+}
+
+// resolveVehicles looks up every vehicle of ids in a batch, keeping a nil
+// entry for the unknown ones.
+func (r *Resolver) resolveVehicles(ctx context.Context, ids []string) ([]*model.Vehicle, error) {
+	return r.loaders(ctx).Vehicles.LoadAll(ctx, ids)
+}
+
+// resolvePilots resolves the characters of ids, leaving out the ones
+// deleted since.
+func (r *Resolver) resolvePilots(ctx context.Context, ids []string) ([]model.Character, error) {
+	chars, err := r.resolveCharacters(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	l := make([]model.Character, 0, len(chars))
+	for _, c := range chars {
+		if c != nil {
+			l = append(l, c)
+		}
+	}
+	return l, nil
+}
+
+// resolveFilms looks up the films of episodes in a batch, leaving out the
+// episodes without one.
+func (r *Resolver) resolveFilms(ctx context.Context, episodes []model.Episode) ([]*model.Film, error) {
+	films, err := r.loaders(ctx).Films.LoadAll(ctx, episodes)
+	if err != nil {
+		return nil, err
+	}
+	l := make([]*model.Film, 0, len(films))
+	for _, f := range films {
+		if f != nil {
+			l = append(l, f)
+		}
+	}
+	return l, nil
+}
+
+func (r *Resolver) resolveFriendConnection(_ context.Context, ids []string, first *int, after *string, last *int, before *string) (*model.FriendsConnection, error) {
+	page, err := model.NewConnection(len(ids), first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.FriendsConnection{
-		Ids:  ids,
-		From: from,
-		To:   to,
+		Connection: page,
+		Ids:        ids,
 	}, nil
 }
 
-func NewResolver() generated.Config {
-	r := Resolver{}
-	r.humans = map[string]model.Human{
-		"1000": {
-			CharacterFields: model.CharacterFields{
-				ID:        "1000",
-				Name:      "Luke Skywalker",
-				FriendIds: []string{"1002", "1003", "2000", "2001"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi},
-			},
-			HeightMeters: 1.72,
-			Mass:         77,
-			StarshipIds:  []string{"3001", "3003"},
-		},
-		"1001": {
-			CharacterFields: model.CharacterFields{
-				ID:        "1001",
-				Name:      "Darth Vader",
-				FriendIds: []string{"1004"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi},
-			},
-			HeightMeters: 2.02,
-			Mass:         136,
-			StarshipIds:  []string{"3002"},
-		},
-		"1002": {
-			CharacterFields: model.CharacterFields{
-				ID:        "1002",
-				Name:      "Han Solo",
-				FriendIds: []string{"1000", "1003", "2001"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi},
-			},
-			HeightMeters: 1.8,
-			Mass:         80,
-			StarshipIds:  []string{"3000", "3003"},
-		},
-		"1003": {
-			CharacterFields: model.CharacterFields{
-				ID:        "1003",
-				Name:      "Leia Organa",
-				FriendIds: []string{"1000", "1002", "2000", "2001"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi},
-			},
-			HeightMeters: 1.5,
-			Mass:         49,
-		},
-		"1004": {
-			CharacterFields: model.CharacterFields{
-				ID:        "1004",
-				Name:      "Wilhuff Tarkin",
-				FriendIds: []string{"1001"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope},
-			},
-			HeightMeters: 1.8,
-			Mass:         0,
-		},
+func (r *Resolver) resolveStarshipsConnection(_ context.Context, ids []string, first *int, after *string, last *int, before *string) (*model.StarshipsConnection, error) {
+	page, err := model.NewConnection(len(ids), first, after, last, before)
+	if err != nil {
+		return nil, err
 	}
 
-	r.droid = map[string]model.Droid{
-		"2000": {
-			CharacterFields: model.CharacterFields{
-				ID:        "2000",
-				Name:      "C-3PO",
-				FriendIds: []string{"1000", "1002", "1003", "2001"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi},
-			},
-			PrimaryFunction: "Protocol",
-		},
-		"2001": {
-			CharacterFields: model.CharacterFields{
-				ID:        "2001",
-				Name:      "R2-D2",
-				FriendIds: []string{"1000", "1002", "1003"},
-				AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi},
-			},
-			PrimaryFunction: "Astromech",
-		},
-	}
+	return &model.StarshipsConnection{
+		Connection: page,
+		Ids:        ids,
+	}, nil
+}
 
-	r.starships = map[string]model.Starship{
-		"3000": {
-			ID:   "3000",
-			Name: "Millennium Falcon",
-			History: [][]int{
-				{1, 2},
-				{4, 5},
-				{1, 2},
-				{3, 2},
-			},
-			Length: 34.37,
-		},
-		"3001": {
-			ID:   "3001",
-			Name: "X-Wing",
-			History: [][]int{
-				{6, 4},
-				{3, 2},
-				{2, 3},
-				{5, 1},
-			},
-			Length: 12.5,
-		},
-		"3002": {
-			ID:   "3002",
-			Name: "TIE Advanced x1",
-			History: [][]int{
-				{3, 2},
-				{7, 2},
-				{6, 4},
-				{3, 2},
-			},
-			Length: 9.2,
-		},
-		"3003": {
-			ID:   "3003",
-			Name: "Imperial shuttle",
-			History: [][]int{
-				{1, 7},
-				{3, 5},
-				{5, 3},
-				{7, 1},
-			},
-			Length: 20,
-		},
+// resolveReviews returns the reviews of episode posted after since, or all
+// of them when since is nil.
+func (r *Resolver) resolveReviews(ctx context.Context, episode model.Episode, since *time.Time) ([]*model.Review, error) {
+	reviews, err := r.reviews.Reviews(ctx, episode)
+	if err != nil {
+		return nil, err
+	}
+	if since == nil {
+		return reviews, nil
 	}
 
-	r.reviews = map[model.Episode][]*model.Review{}
+	var filtered []*model.Review
+	for _, rev := range reviews {
+		if rev.Time.After(*since) {
+			filtered = append(filtered, rev)
+		}
+	}
+	return filtered, nil
+}
 
+// NewResolver builds the gqlgen config for resolvers backed by repo.
+func NewResolver(repo store.Repository, opts ...Option) generated.Config {
+	r := &Resolver{
+		characters: repo.Characters,
+		starships:  repo.Starships,
+		vehicles:   repo.Vehicles,
+		reviews:    repo.Reviews,
+		films:      repo.Films,
+		planets:    repo.Planets,
+		species:    repo.Species,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
 	return generated.Config{
-		Resolvers: &r,
+		Resolvers: r,
+		Directives: generated.DirectiveRoot{
+			Range:  validation.Range,
+			Length: validation.Length,
+		},
+		Complexity: limits.Complexity(),
 	}
 }
@@ -0,0 +1,423 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/MatsuoTakuro/starwars/graph/generated"
+	"github.com/MatsuoTakuro/starwars/graph/loader"
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+	"github.com/MatsuoTakuro/starwars/graph/store/storetest"
+	"github.com/MatsuoTakuro/starwars/graph/validation"
+)
+
+// newClient serves repo the way the server does, loaders included.
+func newClient(repo store.Repository) *client.Client {
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(NewResolver(repo)))
+	return client.New(loader.Middleware(repo, srv))
+}
+
+type createReviewResponse struct {
+	CreateReview struct{ ID string }
+}
+
+func TestConcurrentReviews(t *testing.T) {
+	const writers, readers, perWorker = 8, 8, 10
+
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			c := newClient(storetest.Open(t, backend))
+
+			var wg sync.WaitGroup
+			errs := make(chan error, (writers+readers)*perWorker)
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						var resp createReviewResponse
+						err := c.Post(`mutation($c: String) { createReview(episode: JEDI, review: {stars: 4, commentary: $c}) { id } }`,
+							&resp, client.Var("c", fmt.Sprintf("review %d of writer %d", i, w)))
+						if err != nil {
+							errs <- err
+						}
+					}
+				}(w)
+			}
+			for r := 0; r < readers; r++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						var resp struct {
+							Reviews           []struct{ ID string }
+							ReviewsConnection struct{ TotalCount int }
+						}
+						if err := c.Post(`{ reviews(episode: JEDI) { id } reviewsConnection(episode: JEDI, first: 2) { totalCount } }`, &resp); err != nil {
+							errs <- err
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+
+			var resp struct{ Reviews []struct{ ID string } }
+			c.MustPost(`{ reviews(episode: JEDI) { id } }`, &resp)
+			ids := map[string]bool{}
+			for _, r := range resp.Reviews {
+				if ids[r.ID] {
+					t.Errorf("review %s is listed twice", r.ID)
+				}
+				ids[r.ID] = true
+			}
+			if want := writers * perWorker; len(ids) != want {
+				t.Errorf("got %d reviews, want %d", len(ids), want)
+			}
+		})
+	}
+}
+
+// counter counts the calls made to the repositories it wraps.
+type counter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *counter) add(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[method]++
+}
+
+func (c *counter) get(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+type countingCharacters struct {
+	store.CharacterRepository
+	*counter
+}
+
+func (r countingCharacters) Human(ctx context.Context, id string) (*model.Human, error) {
+	r.add("Human")
+	return r.CharacterRepository.Human(ctx, id)
+}
+
+func (r countingCharacters) Droid(ctx context.Context, id string) (*model.Droid, error) {
+	r.add("Droid")
+	return r.CharacterRepository.Droid(ctx, id)
+}
+
+func (r countingCharacters) CharactersByIDs(ctx context.Context, ids []string) ([]model.Character, error) {
+	r.add("CharactersByIDs")
+	return r.CharacterRepository.CharactersByIDs(ctx, ids)
+}
+
+type countingStarships struct {
+	store.StarshipRepository
+	*counter
+}
+
+func (r countingStarships) Starship(ctx context.Context, id string) (*model.Starship, error) {
+	r.add("Starship")
+	return r.StarshipRepository.Starship(ctx, id)
+}
+
+func (r countingStarships) StarshipsByIDs(ctx context.Context, ids []string) ([]*model.Starship, error) {
+	r.add("StarshipsByIDs")
+	return r.StarshipRepository.StarshipsByIDs(ctx, ids)
+}
+
+// TestBatchedLookups checks that every nesting level of a query costs a
+// single fetch of the characters or starships it reaches.
+func TestBatchedLookups(t *testing.T) {
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			repo := storetest.Open(t, backend)
+			calls := &counter{calls: map[string]int{}}
+			repo.Characters = countingCharacters{repo.Characters, calls}
+			repo.Starships = countingStarships{repo.Starships, calls}
+			c := newClient(repo)
+
+			var resp struct {
+				Hero struct {
+					Name    string
+					Friends []struct {
+						Name    string
+						Friends []struct {
+							Name      string
+							Starships []struct{ Name string }
+						}
+					}
+				}
+			}
+			c.MustPost(`{ hero { name friends { name friends { name starships { name } } } } }`, &resp)
+
+			if got := len(resp.Hero.Friends); got != 3 {
+				t.Fatalf("hero has %d friends, want 3", got)
+			}
+			// The hero, its friends and their friends.
+			if got := calls.get("CharactersByIDs"); got != 3 {
+				t.Errorf("CharactersByIDs called %d times, want 3", got)
+			}
+			if got := calls.get("StarshipsByIDs"); got != 1 {
+				t.Errorf("StarshipsByIDs called %d times, want 1", got)
+			}
+			for _, method := range []string{"Human", "Droid", "Starship"} {
+				if got := calls.get(method); got != 0 {
+					t.Errorf("%s called %d times, want 0", method, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCoordinatesOffTheMap(t *testing.T) {
+	mutations := map[string]string{
+		"recordStarshipPosition": `mutation { recordStarshipPosition(id: "3000", x: 2147483648, y: 0) { position { coordinate { x } } } }`,
+		"track":                  `mutation { createStarship(input: {name: "Probe", length: 1, track: [{x: 0, y: -2147483649}]}) { id } }`,
+		"history":                `mutation { createStarship(input: {name: "Probe", length: 1, history: [[0, 0], [9223372036854775807, 0]]}) { id } }`,
+	}
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			repo := storetest.Open(t, backend)
+			c := newClient(repo)
+			for field, query := range mutations {
+				var resp map[string]interface{}
+				if err := c.Post(query, &resp); err == nil {
+					t.Errorf("%s accepted a coordinate off the map", field)
+				}
+			}
+
+			s, err := repo.Starships.Starship(context.Background(), "3000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s.History) != 4 {
+				t.Errorf("the track of 3000 has %d points, want the 4 of the fixtures", len(s.History))
+			}
+			all, err := repo.Starships.Starships(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 4 {
+				t.Errorf("got %d starships, want the 4 of the fixtures", len(all))
+			}
+		})
+	}
+}
+
+func TestSymmetricFriendships(t *testing.T) {
+	type issues struct {
+		FriendshipIssues []struct{ Kind, CharacterID, FriendID string }
+	}
+	type friends struct {
+		Character struct{ Friends []struct{ ID string } }
+	}
+	friendIDs := func(c *client.Client, id string) []string {
+		var resp friends
+		c.MustPost(`query($id: ID!) { character(id: $id) { friends { id } } }`, &resp, client.Var("id", id))
+		l := []string{}
+		for _, f := range resp.Character.Friends {
+			l = append(l, f.ID)
+		}
+		return l
+	}
+
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			c := newClient(storetest.Open(t, backend))
+			var before issues
+			c.MustPost(`{ friendshipIssues { kind characterId friendId } }`, &before)
+
+			var human struct{ CreateHuman struct{ ID string } }
+			c.MustPost(`mutation { createHuman(input: {name: "Wedge", friendIds: ["1000", "2001"]}) { id } }`, &human)
+			wedge := human.CreateHuman.ID
+			var droid struct{ CreateDroid struct{ ID string } }
+			c.MustPost(`mutation($f: [ID!]) { createDroid(input: {name: "R5-D4", friendIds: $f}) { id } }`,
+				&droid, client.Var("f", []string{wedge}))
+			r5 := droid.CreateDroid.ID
+
+			for id, want := range map[string]string{"1000": wedge, "2001": wedge} {
+				if !slices.Contains(friendIDs(c, id), want) {
+					t.Errorf("friends of %s = %v, want them to hold %s", id, friendIDs(c, id), want)
+				}
+			}
+			if got, want := friendIDs(c, wedge), []string{"1000", "2001", r5}; !slices.Equal(got, want) {
+				t.Errorf("friends of %s = %v, want %v", wedge, got, want)
+			}
+
+			var updated struct{ UpdateCharacter struct{ ID string } }
+			c.MustPost(`mutation($id: ID!) { updateCharacter(id: $id, input: {friendIds: ["1000", "1003"]}) { id } }`,
+				&updated, client.Var("id", wedge))
+			if slices.Contains(friendIDs(c, "2001"), wedge) {
+				t.Errorf("friends of 2001 = %v, want them to have dropped %s", friendIDs(c, "2001"), wedge)
+			}
+			if got := friendIDs(c, r5); len(got) != 0 {
+				t.Errorf("friends of %s = %v, want none", r5, got)
+			}
+			if !slices.Contains(friendIDs(c, "1003"), wedge) {
+				t.Errorf("friends of 1003 = %v, want them to hold %s", friendIDs(c, "1003"), wedge)
+			}
+
+			var after issues
+			c.MustPost(`{ friendshipIssues { kind characterId friendId } }`, &after)
+			if !reflect.DeepEqual(after, before) {
+				t.Errorf("friendshipIssues = %+v, want the %+v of the fixtures", after, before)
+			}
+		})
+	}
+}
+
+// sharedTrack hands out the same track, with room to grow, to every
+// reader of a starship, as a store sharing its slices with readers may.
+type sharedTrack struct {
+	store.StarshipRepository
+	history []model.HistoryPoint
+}
+
+func (r sharedTrack) Starship(ctx context.Context, id string) (*model.Starship, error) {
+	s, err := r.StarshipRepository.Starship(ctx, id)
+	if s != nil {
+		s.History = r.history
+	}
+	return s, err
+}
+
+// TestRecordPositionKeepsTrack checks that recordStarshipPosition does not
+// write into the spare capacity of the track it was handed.
+func TestRecordPositionKeepsTrack(t *testing.T) {
+	repo := storetest.Open(t, "memory")
+	history := make([]model.HistoryPoint, 1, 2)
+	repo.Starships = sharedTrack{repo.Starships, history}
+	c := newClient(repo)
+
+	var resp struct {
+		RecordStarshipPosition struct {
+			Starship struct {
+				Track []struct{ Coordinate struct{ X, Y int } }
+			}
+			Position struct{ Coordinate struct{ X, Y int } }
+		}
+	}
+	c.MustPost(`mutation { recordStarshipPosition(id: "3000", x: 8, y: 9) { starship { track { coordinate { x y } } } position { coordinate { x y } } } }`, &resp)
+
+	if spare := history[:2][1]; spare.Coordinate != (model.Coordinate{}) {
+		t.Errorf("the shared track got %+v appended", spare.Coordinate)
+	}
+	m := resp.RecordStarshipPosition
+	if p := m.Position.Coordinate; p.X != 8 || p.Y != 9 {
+		t.Errorf("position = %+v, want 8, 9", p)
+	}
+	if n := len(m.Starship.Track); n != 2 {
+		t.Errorf("track has %d points, want 2", n)
+	}
+}
+
+func TestDuplicateIDs(t *testing.T) {
+	mutations := map[string]string{
+		"createHuman friends":       `mutation { createHuman(input: {name: "Wedge", friendIds: ["1000", "1000"]}) { id } }`,
+		"createHuman starships":     `mutation { createHuman(input: {name: "Wedge", starshipIds: ["3000", "3001", "3000"]}) { id } }`,
+		"createDroid vehicles":      `mutation { createDroid(input: {name: "R5-D4", vehicleIds: ["6000", "6000"]}) { id } }`,
+		"updateCharacter friends":   `mutation { updateCharacter(id: "1000", input: {friendIds: ["1002", "1002"]}) { id } }`,
+		"updateCharacter starships": `mutation { updateCharacter(id: "2001", input: {starshipIds: ["3000", "3000"]}) { id } }`,
+	}
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			ctx := context.Background()
+			repo := storetest.Open(t, backend)
+			c := newClient(repo)
+			wantHumans, _ := repo.Characters.Humans(ctx)
+			wantDroids, _ := repo.Characters.Droids(ctx)
+
+			for field, query := range mutations {
+				var resp map[string]interface{}
+				err := c.Post(query, &resp)
+				if err == nil || !strings.Contains(err.Error(), validation.CodeBadUserInput) {
+					t.Errorf("%s: error = %v, want a %s error", field, err, validation.CodeBadUserInput)
+				}
+			}
+
+			if humans, _ := repo.Characters.Humans(ctx); !reflect.DeepEqual(humans, wantHumans) {
+				t.Errorf("humans changed to %+v", humans)
+			}
+			if droids, _ := repo.Characters.Droids(ctx); !reflect.DeepEqual(droids, wantDroids) {
+				t.Errorf("droids changed to %+v", droids)
+			}
+		})
+	}
+}
+
+func TestDistanceTravelled(t *testing.T) {
+	// The Millennium Falcon goes from 1, 2 to 4, 5, back to 1, 2, then to
+	// 3, 2 of the map, whose unit is a light-year.
+	lightYears := 6*math.Sqrt2 + 2
+	tests := []struct {
+		unit string
+		want float64
+	}{
+		{unit: "", want: lightYears * 9.4607304725808e15},
+		{unit: "METER", want: lightYears * 9.4607304725808e15},
+		{unit: "KILOMETER", want: lightYears * 9.4607304725808e12},
+		{unit: "FOOT", want: lightYears * 9.4607304725808e15 / 0.3048},
+		{unit: "LIGHT_YEAR", want: lightYears},
+	}
+	c := newClient(storetest.Open(t, "memory"))
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			args := ""
+			if tt.unit != "" {
+				args = "(unit: " + tt.unit + ")"
+			}
+			var resp struct {
+				Starship struct{ DistanceTravelled float64 }
+			}
+			c.MustPost(`{ starship(id: "3000") { distanceTravelled`+args+` } }`, &resp)
+			if got := resp.Starship.DistanceTravelled; math.Abs(got-tt.want) > 1e-9*tt.want {
+				t.Errorf("distanceTravelled = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNoStarships checks that characters without starships list none
+// rather than null, as the schema promises.
+func TestNoStarships(t *testing.T) {
+	tests := []struct {
+		query string
+		path  []string
+	}{
+		{`{ character(id: "2000") { ... on Droid { starships { id } } } }`, []string{"character", "starships"}},
+		{`mutation { createHuman(input: {name: "Biggs"}) { starships { id } } }`, []string{"createHuman", "starships"}},
+		{`{ droid(id: "2000") { starshipsConnection { starships { id } } } }`, []string{"droid", "starshipsConnection", "starships"}},
+	}
+	c := newClient(storetest.Open(t, "memory"))
+	for _, tt := range tests {
+		var resp interface{}
+		if err := c.Post(tt.query, &resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range tt.path {
+			m, _ := resp.(map[string]interface{})
+			resp = m[field]
+		}
+		if got, ok := resp.([]interface{}); !ok || len(got) != 0 {
+			t.Errorf("%s: starships = %#v, want an empty list", tt.query, resp)
+		}
+	}
+}
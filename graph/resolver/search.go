@@ -0,0 +1,305 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/search"
+)
+
+// search returns the entities of the given types, or of every type when
+// types is empty, matching text along with where they matched. The best
+// matches come first, ties being ordered by name then ID.
+func (r *Resolver) search(ctx context.Context, text string, types []model.SearchType) ([]model.SearchHit, error) {
+	ix, err := r.searchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, t := range types {
+		wanted[t.String()] = true
+	}
+
+	q := search.NewQuery(text)
+	var hits []search.Hit
+	var keys []search.Key
+	for _, hit := range ix.Search(q) {
+		if len(wanted) > 0 && !wanted[hit.Key.Kind] {
+			continue
+		}
+		hits = append(hits, hit)
+		keys = append(keys, hit.Key)
+	}
+
+	results, err := r.searchResults(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]model.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		// Entities deleted since the index was searched are missing.
+		if res, ok := results[hit.Key]; ok {
+			l = append(l, model.SearchHit{
+				Result:     res,
+				Highlights: highlights(q.Highlight(hit.Document)),
+			})
+		}
+	}
+	return l, nil
+}
+
+// searchResults looks up the entities of keys, batching the lookups of
+// each kind. Unknown keys are left out of the result.
+func (r *Resolver) searchResults(ctx context.Context, keys []search.Key) (map[search.Key]model.SearchResult, error) {
+	var characterKeys, starshipKeys, reviewKeys, filmKeys, planetKeys, speciesKeys []search.Key
+	for _, k := range keys {
+		switch model.SearchType(k.Kind) {
+		case model.SearchTypeHuman, model.SearchTypeDroid:
+			characterKeys = append(characterKeys, k)
+		case model.SearchTypeStarship:
+			starshipKeys = append(starshipKeys, k)
+		case model.SearchTypeReview:
+			reviewKeys = append(reviewKeys, k)
+		case model.SearchTypeFilm:
+			filmKeys = append(filmKeys, k)
+		case model.SearchTypePlanet:
+			planetKeys = append(planetKeys, k)
+		case model.SearchTypeSpecies:
+			speciesKeys = append(speciesKeys, k)
+		}
+	}
+
+	results := make(map[search.Key]model.SearchResult, len(keys))
+	characters, err := r.resolveCharacters(ctx, keyIDs(characterKeys))
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range characters {
+		if c != nil {
+			results[characterKeys[i]] = c.(model.SearchResult)
+		}
+	}
+	starships, err := r.resolveStarships(ctx, keyIDs(starshipKeys))
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range starships {
+		if s != nil {
+			results[starshipKeys[i]] = s
+		}
+	}
+	if len(reviewKeys) > 0 {
+		reviews, err := r.reviews.ReviewsByIDs(ctx, keyIDs(reviewKeys))
+		if err != nil {
+			return nil, err
+		}
+		for i, rev := range reviews {
+			if rev != nil {
+				results[reviewKeys[i]] = rev
+			}
+		}
+	}
+	episodes := make([]model.Episode, len(filmKeys))
+	for i, k := range filmKeys {
+		episodes[i] = model.Episode(k.ID)
+	}
+	films, err := r.loaders(ctx).Films.LoadAll(ctx, episodes)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range films {
+		if f != nil {
+			results[filmKeys[i]] = f
+		}
+	}
+	planets, err := r.loaders(ctx).Planets.LoadAll(ctx, keyIDs(planetKeys))
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range planets {
+		if p != nil {
+			results[planetKeys[i]] = p
+		}
+	}
+	species, err := r.loaders(ctx).Species.LoadAll(ctx, keyIDs(speciesKeys))
+	if err != nil {
+		return nil, err
+	}
+	for i, sp := range species {
+		if sp != nil {
+			results[speciesKeys[i]] = sp
+		}
+	}
+	return results, nil
+}
+
+func keyIDs(keys []search.Key) []string {
+	ids := make([]string, len(keys))
+	for i, k := range keys {
+		ids[i] = k.ID
+	}
+	return ids
+}
+
+// highlights converts the highlights of the search package to the model.
+func highlights(l []search.Highlight) []*model.SearchHighlight {
+	hs := make([]*model.SearchHighlight, len(l))
+	for i, h := range l {
+		matches := make([]*model.TextRange, len(h.Matches))
+		for j, m := range h.Matches {
+			matches[j] = &model.TextRange{Start: m.Start, End: m.End}
+		}
+		hs[i] = &model.SearchHighlight{
+			Field:   h.Field,
+			Snippet: h.Snippet,
+			Matches: matches,
+		}
+	}
+	return hs
+}
+
+// searchIndex returns the search index, building it from the store on the
+// first call.
+func (r *Resolver) searchIndex(ctx context.Context) (*search.Index, error) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.index != nil {
+		return r.index, nil
+	}
+
+	ix := search.NewIndex()
+	humans, err := r.characters.Humans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range humans {
+		ix.Put(humanDocument(h))
+	}
+	droids, err := r.characters.Droids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range droids {
+		ix.Put(droidDocument(d))
+	}
+	starships, err := r.starships.Starships(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range starships {
+		ix.Put(starshipDocument(s))
+	}
+	films, err := r.films.Films(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range films {
+		ix.Put(filmDocument(f))
+	}
+	planets, err := r.planets.Planets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range planets {
+		ix.Put(planetDocument(p))
+	}
+	species, err := r.species.AllSpecies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sp := range species {
+		ix.Put(speciesDocument(sp))
+	}
+	for _, e := range model.AllEpisode {
+		reviews, err := r.reviews.Reviews(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		for _, rev := range reviews {
+			ix.Put(reviewDocument(rev))
+		}
+	}
+
+	r.index = ix
+	return ix, nil
+}
+
+// updateIndex applies update to the search index once it is built. Until
+// then there is nothing to update, as the build reads the store afresh.
+func (r *Resolver) updateIndex(update func(ix *search.Index)) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.index != nil {
+		update(r.index)
+	}
+}
+
+func humanDocument(h *model.Human) search.Document {
+	return search.Document{
+		Key:   search.Key{Kind: model.SearchTypeHuman.String(), ID: h.ID},
+		Title: search.Field{Name: "name", Text: h.Name},
+	}
+}
+
+func droidDocument(d *model.Droid) search.Document {
+	return search.Document{
+		Key:   search.Key{Kind: model.SearchTypeDroid.String(), ID: d.ID},
+		Title: search.Field{Name: "name", Text: d.Name},
+		Body:  []search.Field{{Name: "primaryFunction", Text: d.PrimaryFunction}},
+	}
+}
+
+func starshipDocument(s *model.Starship) search.Document {
+	return search.Document{
+		Key:   search.Key{Kind: model.SearchTypeStarship.String(), ID: s.ID},
+		Title: search.Field{Name: "name", Text: s.Name},
+	}
+}
+
+// reviewDocument indexes the commentary of a review, which has no title.
+func reviewDocument(rev *model.Review) search.Document {
+	d := search.Document{
+		Key: search.Key{Kind: model.SearchTypeReview.String(), ID: rev.ID},
+	}
+	if rev.Commentary != nil {
+		d.Body = []search.Field{{Name: "commentary", Text: *rev.Commentary}}
+	}
+	return d
+}
+
+func filmDocument(f *model.Film) search.Document {
+	return search.Document{
+		Key:   search.Key{Kind: model.SearchTypeFilm.String(), ID: f.Episode.String()},
+		Title: search.Field{Name: "title", Text: f.Title},
+		Body: []search.Field{
+			{Name: "director", Text: f.Director},
+			{Name: "openingCrawl", Text: f.OpeningCrawl},
+		},
+	}
+}
+
+func planetDocument(p *model.Planet) search.Document {
+	return search.Document{
+		Key:   search.Key{Kind: model.SearchTypePlanet.String(), ID: p.ID},
+		Title: search.Field{Name: "name", Text: p.Name},
+		Body: []search.Field{
+			{Name: "climate", Text: p.Climate},
+			{Name: "terrain", Text: p.Terrain},
+		},
+	}
+}
+
+func speciesDocument(sp *model.Species) search.Document {
+	return search.Document{
+		Key:   search.Key{Kind: model.SearchTypeSpecies.String(), ID: sp.ID},
+		Title: search.Field{Name: "name", Text: sp.Name},
+		Body: []search.Field{
+			{Name: "classification", Text: sp.Classification},
+			{Name: "language", Text: sp.Language},
+		},
+	}
+}
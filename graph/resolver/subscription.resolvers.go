@@ -0,0 +1,32 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/MatsuoTakuro/starwars/graph/generated"
+	"github.com/MatsuoTakuro/starwars/graph/model"
+)
+
+func (r *subscriptionResolver) ReviewAdded(ctx context.Context, episode *model.Episode) (<-chan *model.Review, error) {
+	topic := ""
+	if episode != nil {
+		topic = episode.String()
+	}
+	return r.reviewAdded.Subscribe(ctx, topic), nil
+}
+
+func (r *subscriptionResolver) StarshipMoved(ctx context.Context, id *string) (<-chan *model.StarshipMovement, error) {
+	topic := ""
+	if id != nil {
+		topic = *id
+	}
+	return r.starshipMoved.Subscribe(ctx, topic), nil
+}
+
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
+type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/MatsuoTakuro/starwars/graph/generated"
+	"github.com/MatsuoTakuro/starwars/graph/loader"
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store/storetest"
+)
+
+type reviewAddedResponse struct {
+	ReviewAdded struct {
+		Episode    model.Episode
+		Stars      int
+		Commentary *string
+	}
+}
+
+func TestReviewAdded(t *testing.T) {
+	cfg := NewResolver(storetest.Open(t, "memory"))
+	r := cfg.Resolvers.(*Resolver)
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(cfg)))
+
+	jedi := c.Websocket(`subscription { reviewAdded(episode: JEDI) { episode stars commentary } }`)
+	defer jedi.Close()
+	all := c.Websocket(`subscription { reviewAdded { episode stars commentary } }`)
+	defer all.Close()
+	// Subscriptions are registered once the server has run their resolver,
+	// which happens after the handshake the client waits for.
+	waitFor(t, func() bool { return r.reviewAdded.Subscribers("JEDI") == 1 && r.reviewAdded.Subscribers("") == 1 })
+
+	var created createReviewResponse
+	c.MustPost(`mutation { createReview(episode: EMPIRE, review: {stars: 3}) { id } }`, &created)
+	c.MustPost(`mutation { createReview(episode: JEDI, review: {stars: 5, commentary: "Great"}) { id } }`, &created)
+
+	var resp reviewAddedResponse
+	if err := all.Next(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ReviewAdded.Episode != model.EpisodeEmpire || resp.ReviewAdded.Stars != 3 {
+		t.Errorf("first review on every episode = %+v, want 3 stars on EMPIRE", resp.ReviewAdded)
+	}
+	if err := all.Next(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ReviewAdded.Episode != model.EpisodeJedi {
+		t.Errorf("second review on every episode = %+v, want one on JEDI", resp.ReviewAdded)
+	}
+
+	// The JEDI subscription skips the EMPIRE review.
+	if err := jedi.Next(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ReviewAdded.Episode != model.EpisodeJedi || resp.ReviewAdded.Stars != 5 ||
+		resp.ReviewAdded.Commentary == nil || *resp.ReviewAdded.Commentary != "Great" {
+		t.Errorf("review on JEDI = %+v, want 5 stars on JEDI", resp.ReviewAdded)
+	}
+}
+
+// TestReviewAddedUnsubscribe checks that closing the socket cancels the
+// context of the subscription, which removes it from the broker.
+func TestReviewAddedUnsubscribe(t *testing.T) {
+	cfg := NewResolver(storetest.Open(t, "memory"))
+	r := cfg.Resolvers.(*Resolver)
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(cfg)))
+
+	sub := c.Websocket(`subscription { reviewAdded(episode: JEDI) { stars } }`)
+	waitFor(t, func() bool { return r.reviewAdded.Subscribers("JEDI") == 1 })
+
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return r.reviewAdded.Subscribers("JEDI") == 0 })
+
+	// Publishing without subscribers must not block the mutation.
+	var created createReviewResponse
+	c.MustPost(`mutation { createReview(episode: JEDI, review: {stars: 1}) { id } }`, &created)
+}
+
+type starshipMovedResponse struct {
+	StarshipMoved struct {
+		Starship struct{ ID string }
+		Position struct {
+			Coordinate   struct{ X, Y int }
+			LocationName *string
+		}
+	}
+}
+
+func TestStarshipMoved(t *testing.T) {
+	repo := storetest.Open(t, "memory")
+	cfg := NewResolver(repo)
+	r := cfg.Resolvers.(*Resolver)
+	c := client.New(loader.Middleware(repo, handler.NewDefaultServer(generated.NewExecutableSchema(cfg))))
+
+	const fields = `{ starship { id } position { coordinate { x y } locationName } }`
+	falcon := c.Websocket(`subscription { starshipMoved(id: "3000") ` + fields + ` }`)
+	defer falcon.Close()
+	xWing := c.Websocket(`subscription { starshipMoved(id: "3001") ` + fields + ` }`)
+	defer xWing.Close()
+	waitFor(t, func() bool {
+		return r.starshipMoved.Subscribers("3000") == 1 && r.starshipMoved.Subscribers("3001") == 1
+	})
+
+	var moved struct {
+		RecordStarshipPosition struct{ Starship struct{ ID string } }
+	}
+	c.MustPost(`mutation { recordStarshipPosition(id: "3000", x: 4, y: -7, locationName: "Bespin") { starship { id } } }`, &moved)
+	c.MustPost(`mutation { recordStarshipPosition(id: "3001", x: 8, y: 9) { starship { id } } }`, &moved)
+
+	var resp starshipMovedResponse
+	if err := falcon.Next(&resp); err != nil {
+		t.Fatal(err)
+	}
+	got := resp.StarshipMoved
+	if got.Starship.ID != "3000" || got.Position.Coordinate.X != 4 || got.Position.Coordinate.Y != -7 ||
+		got.Position.LocationName == nil || *got.Position.LocationName != "Bespin" {
+		t.Errorf("movement of 3000 = %+v, want 3000 at 4, -7 on Bespin", got)
+	}
+
+	// The subscription of 3001 skips the movement of 3000.
+	if err := xWing.Next(&resp); err != nil {
+		t.Fatal(err)
+	}
+	got = resp.StarshipMoved
+	if got.Starship.ID != "3001" || got.Position.Coordinate.X != 8 || got.Position.Coordinate.Y != 9 {
+		t.Errorf("movement of 3001 = %+v, want 3001 at 8, 9", got)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -5,76 +5,247 @@ package resolver
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"github.com/MatsuoTakuro/starwars/graph/generated"
 	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/units"
 )
 
 func (r *droidResolver) Friends(ctx context.Context, obj *model.Droid) ([]model.Character, error) {
 	return r.resolveCharacters(ctx, obj.FriendIds)
 }
 
-func (r *droidResolver) FriendsConnection(ctx context.Context, obj *model.Droid, first *int, after *string) (*model.FriendsConnection, error) {
-	return r.resolveFriendConnection(ctx, obj.FriendIds, first, after)
+func (r *droidResolver) FriendsConnection(ctx context.Context, obj *model.Droid, first *int, after *string, last *int, before *string) (*model.FriendsConnection, error) {
+	return r.resolveFriendConnection(ctx, obj.FriendIds, first, after, last, before)
+}
+
+func (r *droidResolver) Films(ctx context.Context, obj *model.Droid) ([]*model.Film, error) {
+	return r.resolveFilms(ctx, obj.AppearsIn)
+}
+
+func (r *droidResolver) Starships(ctx context.Context, obj *model.Droid) ([]*model.Starship, error) {
+	starships, err := r.resolveStarships(ctx, obj.StarshipIds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Starship, 0, len(starships))
+	for _, s := range starships {
+		if s != nil {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (r *droidResolver) StarshipsConnection(ctx context.Context, obj *model.Droid, first *int, after *string, last *int, before *string) (*model.StarshipsConnection, error) {
+	return r.resolveStarshipsConnection(ctx, obj.StarshipIds, first, after, last, before)
+}
+
+func (r *droidResolver) Vehicles(ctx context.Context, obj *model.Droid) ([]*model.Vehicle, error) {
+	vehicles, err := r.resolveVehicles(ctx, obj.VehicleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Vehicle, 0, len(vehicles))
+	for _, v := range vehicles {
+		if v != nil {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+func (r *filmResolver) Heroes(ctx context.Context, obj *model.Film) ([]model.Character, error) {
+	return r.resolveCharacters(ctx, obj.HeroIds)
 }
 
 func (r *friendsConnectionResolver) Edges(ctx context.Context, obj *model.FriendsConnection) ([]*model.FriendsEdge, error) {
-	friends, err := r.resolveCharacters(ctx, obj.Ids)
+	friends, err := r.resolveCharacters(ctx, obj.Ids[obj.From:obj.To])
 	if err != nil {
 		return nil, err
 	}
 
-	edges := make([]*model.FriendsEdge, obj.To-obj.From)
+	edges := make([]*model.FriendsEdge, len(friends))
 	for i := range edges {
 		edges[i] = &model.FriendsEdge{
 			Cursor: model.EncodeCursor(obj.From + i),
-			Node:   friends[obj.From+i],
+			Node:   friends[i],
 		}
 	}
 	return edges, nil
 }
 
 func (r *friendsConnectionResolver) Friends(ctx context.Context, obj *model.FriendsConnection) ([]model.Character, error) {
-	return r.resolveCharacters(ctx, obj.Ids)
+	return r.resolveCharacters(ctx, obj.Ids[obj.From:obj.To])
+}
+
+func (r *humanResolver) Mass(ctx context.Context, obj *model.Human, unit *units.MassUnit) (*float64, error) {
+	mass, err := units.Mass(obj.Mass, unit)
+	if err != nil {
+		return nil, err
+	}
+	return &mass, nil
 }
 
 func (r *humanResolver) Friends(ctx context.Context, obj *model.Human) ([]model.Character, error) {
 	return r.resolveCharacters(ctx, obj.FriendIds)
 }
 
-func (r *humanResolver) FriendsConnection(ctx context.Context, obj *model.Human, first *int, after *string) (*model.FriendsConnection, error) {
-	return r.resolveFriendConnection(ctx, obj.FriendIds, first, after)
+func (r *humanResolver) FriendsConnection(ctx context.Context, obj *model.Human, first *int, after *string, last *int, before *string) (*model.FriendsConnection, error) {
+	return r.resolveFriendConnection(ctx, obj.FriendIds, first, after, last, before)
+}
+
+func (r *humanResolver) Films(ctx context.Context, obj *model.Human) ([]*model.Film, error) {
+	return r.resolveFilms(ctx, obj.AppearsIn)
 }
 
 func (r *humanResolver) Starships(ctx context.Context, obj *model.Human) ([]*model.Starship, error) {
-	var result []*model.Starship
-	for _, id := range obj.StarshipIds {
-		char, err := r.Query().Starship(ctx, id)
-		if err != nil {
-			return nil, err
+	starships, err := r.resolveStarships(ctx, obj.StarshipIds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Starship, 0, len(starships))
+	for _, s := range starships {
+		if s != nil {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (r *humanResolver) StarshipsConnection(ctx context.Context, obj *model.Human, first *int, after *string, last *int, before *string) (*model.StarshipsConnection, error) {
+	return r.resolveStarshipsConnection(ctx, obj.StarshipIds, first, after, last, before)
+}
+
+func (r *humanResolver) Vehicles(ctx context.Context, obj *model.Human) ([]*model.Vehicle, error) {
+	vehicles, err := r.resolveVehicles(ctx, obj.VehicleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Vehicle, 0, len(vehicles))
+	for _, v := range vehicles {
+		if v != nil {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+func (r *humanResolver) Homeworld(ctx context.Context, obj *model.Human) (*model.Planet, error) {
+	if obj.HomeworldID == "" {
+		return nil, nil
+	}
+	return r.loaders(ctx).Planets.Load(ctx, obj.HomeworldID)
+}
+
+func (r *humanResolver) Species(ctx context.Context, obj *model.Human) (*model.Species, error) {
+	if obj.SpeciesID == "" {
+		return nil, nil
+	}
+	return r.loaders(ctx).Species.Load(ctx, obj.SpeciesID)
+}
+
+func (r *planetResolver) Residents(ctx context.Context, obj *model.Planet) ([]*model.Human, error) {
+	ids, err := r.loaders(ctx).Residents.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	characters, err := r.resolveCharacters(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	// Humans deleted since their IDs were loaded are nil.
+	humans := make([]*model.Human, 0, len(characters))
+	for _, c := range characters {
+		if h, ok := c.(*model.Human); ok {
+			humans = append(humans, h)
+		}
+	}
+	return humans, nil
+}
+
+func (r *starshipResolver) Length(ctx context.Context, obj *model.Starship, unit *units.LengthUnit) (float64, error) {
+	return units.Length(obj.Length, unit)
+}
+
+func (r *starshipResolver) History(ctx context.Context, obj *model.Starship) ([][]int, error) {
+	history := make([][]int, len(obj.History))
+	for i, p := range obj.History {
+		history[i] = []int{p.Coordinate.X, p.Coordinate.Y}
+	}
+	return history, nil
+}
+
+func (r *starshipResolver) Track(ctx context.Context, obj *model.Starship, since *time.Time, until *time.Time) ([]*model.HistoryPoint, error) {
+	return trackBetween(obj.History, since, until), nil
+}
+
+func (r *starshipResolver) DistanceTravelled(ctx context.Context, obj *model.Starship, unit *units.LengthUnit) (float64, error) {
+	return units.Length(obj.Distance()*units.MetersPerLightYear, unit)
+}
+
+func (r *starshipResolver) Pilots(ctx context.Context, obj *model.Starship) ([]model.Character, error) {
+	ids, err := r.loaders(ctx).StarshipPilots.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.resolvePilots(ctx, ids)
+}
+
+func (r *starshipsConnectionResolver) Edges(ctx context.Context, obj *model.StarshipsConnection) ([]*model.StarshipsEdge, error) {
+	starships, err := r.resolveStarships(ctx, obj.Ids[obj.From:obj.To])
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*model.StarshipsEdge, len(starships))
+	for i := range edges {
+		edges[i] = &model.StarshipsEdge{
+			Cursor: model.EncodeCursor(obj.From + i),
+			Node:   starships[i],
 		}
-		if char != nil {
-			result = append(result, char)
+	}
+	return edges, nil
+}
+
+func (r *starshipsConnectionResolver) Starships(ctx context.Context, obj *model.StarshipsConnection) ([]*model.Starship, error) {
+	starships, err := r.resolveStarships(ctx, obj.Ids[obj.From:obj.To])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Starship, 0, len(starships))
+	for _, s := range starships {
+		if s != nil {
+			result = append(result, s)
 		}
 	}
 	return result, nil
 }
 
-func (r *starshipResolver) Length(ctx context.Context, obj *model.Starship, unit *model.LengthUnit) (float64, error) {
-	switch *unit {
-	case model.LengthUnitMeter, "":
-		return obj.Length, nil
-	case model.LengthUnitFoot:
-		return obj.Length * 3.28084, nil
-	default:
-		return 0, errors.New("invalid unit")
+func (r *vehicleResolver) Length(ctx context.Context, obj *model.Vehicle, unit *units.LengthUnit) (float64, error) {
+	return units.Length(obj.Length, unit)
+}
+
+func (r *vehicleResolver) Pilots(ctx context.Context, obj *model.Vehicle) ([]model.Character, error) {
+	ids, err := r.loaders(ctx).VehiclePilots.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
 	}
+	return r.resolvePilots(ctx, ids)
 }
 
 // Droid returns generated.DroidResolver implementation.
 func (r *Resolver) Droid() generated.DroidResolver { return &droidResolver{r} }
 
+// Film returns generated.FilmResolver implementation.
+func (r *Resolver) Film() generated.FilmResolver { return &filmResolver{r} }
+
 // FriendsConnection returns generated.FriendsConnectionResolver implementation.
 func (r *Resolver) FriendsConnection() generated.FriendsConnectionResolver {
 	return &friendsConnectionResolver{r}
@@ -83,10 +254,25 @@ func (r *Resolver) FriendsConnection() generated.FriendsConnectionResolver {
 // Human returns generated.HumanResolver implementation.
 func (r *Resolver) Human() generated.HumanResolver { return &humanResolver{r} }
 
+// Planet returns generated.PlanetResolver implementation.
+func (r *Resolver) Planet() generated.PlanetResolver { return &planetResolver{r} }
+
 // Starship returns generated.StarshipResolver implementation.
 func (r *Resolver) Starship() generated.StarshipResolver { return &starshipResolver{r} }
 
+// StarshipsConnection returns generated.StarshipsConnectionResolver implementation.
+func (r *Resolver) StarshipsConnection() generated.StarshipsConnectionResolver {
+	return &starshipsConnectionResolver{r}
+}
+
+// Vehicle returns generated.VehicleResolver implementation.
+func (r *Resolver) Vehicle() generated.VehicleResolver { return &vehicleResolver{r} }
+
 type droidResolver struct{ *Resolver }
+type filmResolver struct{ *Resolver }
 type friendsConnectionResolver struct{ *Resolver }
 type humanResolver struct{ *Resolver }
+type planetResolver struct{ *Resolver }
 type starshipResolver struct{ *Resolver }
+type starshipsConnectionResolver struct{ *Resolver }
+type vehicleResolver struct{ *Resolver }
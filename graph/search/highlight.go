@@ -0,0 +1,134 @@
+package search
+
+import (
+	"unicode"
+)
+
+const (
+	// snippetLength is the number of characters past which the text of a
+	// highlight is cut around its first match.
+	snippetLength = 120
+	// snippetContext is the number of characters kept before the first
+	// match of a cut text.
+	snippetContext = 30
+	// ellipsis marks the ends of a cut text.
+	ellipsis = "…"
+)
+
+// Range locates a part of a text, as offsets counted in runes. End is the
+// offset just past the last rune.
+type Range struct {
+	Start int
+	End   int
+}
+
+// Highlight is an excerpt of a document field around the words matching a
+// query.
+type Highlight struct {
+	Field   string
+	Snippet string
+	// Matches locates the matching words in Snippet.
+	Matches []Range
+}
+
+// Highlight returns, for every field of d holding words that match a
+// word of the query, an excerpt of the field locating them. The title
+// comes first, then the body in order. An empty query highlights nothing.
+func (q Query) Highlight(d Document) []Highlight {
+	if len(q.tokens) == 0 {
+		return nil
+	}
+	var l []Highlight
+	for _, f := range append([]Field{d.Title}, d.Body...) {
+		if h, ok := q.highlight(f); ok {
+			l = append(l, h)
+		}
+	}
+	return l
+}
+
+func (q Query) highlight(f Field) (Highlight, bool) {
+	text := []rune(f.Text)
+	var matches []Range
+	for _, w := range wordRanges(text) {
+		if q.matches(Normalize(string(text[w.Start:w.End]))) {
+			matches = append(matches, w)
+		}
+	}
+	if len(matches) == 0 {
+		return Highlight{}, false
+	}
+
+	start, end := 0, len(text)
+	if len(text) > snippetLength {
+		start = matches[0].Start - snippetContext
+		if start < 0 {
+			start = 0
+		}
+		// Do not cut a word in two, nor open on blanks.
+		for start > 0 && start < matches[0].Start && (isWordRune(text[start-1]) || unicode.IsSpace(text[start])) {
+			start++
+		}
+		if end = start + snippetLength; end > len(text) {
+			end = len(text)
+		}
+		for end < len(text) && end > matches[0].End && isWordRune(text[end]) {
+			end--
+		}
+		for end > matches[0].End && unicode.IsSpace(text[end-1]) {
+			end--
+		}
+	}
+
+	h := Highlight{Field: f.Name}
+	shift := -start
+	if start > 0 {
+		h.Snippet = ellipsis
+		shift += len([]rune(ellipsis))
+	}
+	h.Snippet += string(text[start:end])
+	if end < len(text) {
+		h.Snippet += ellipsis
+	}
+	for _, m := range matches {
+		if m.End <= end {
+			h.Matches = append(h.Matches, Range{Start: m.Start + shift, End: m.End + shift})
+		}
+	}
+	return h, true
+}
+
+// matches reports whether the normalized word w matches a word of q.
+func (q Query) matches(w string) bool {
+	for _, t := range q.tokens {
+		if MatchToken(t, w) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wordRanges locates the words of text, split as Tokens splits them.
+func wordRanges(text []rune) []Range {
+	var l []Range
+	start := -1
+	for i, r := range text {
+		switch {
+		case isWordRune(r) && start < 0:
+			start = i
+		case !isWordRune(r) && start >= 0:
+			l = append(l, Range{Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		l = append(l, Range{Start: start, End: len(text)})
+	}
+	return l
+}
+
+// isWordRune reports whether r belongs to a word. Marks are kept so that
+// decomposed accents do not split words, as Normalize removes them.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
@@ -0,0 +1,139 @@
+package search
+
+import (
+	"sort"
+	"sync"
+)
+
+// Hit is a document matching a query.
+type Hit struct {
+	Document
+	Score int
+}
+
+// Index is an inverted index mapping every word to the documents holding
+// it. Queries only score the documents sharing words with them, instead
+// of scanning the whole dataset. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[Key]*prepared
+	postings map[string]map[Key]struct{}
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[Key]*prepared{},
+		postings: map[string]map[Key]struct{}{},
+	}
+}
+
+// Put adds d to the index, replacing the document with the same key.
+func (ix *Index) Put(d Document) {
+	p := prepare(d)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(d.Key)
+	ix.docs[d.Key] = p
+	for _, w := range p.words() {
+		if ix.postings[w] == nil {
+			ix.postings[w] = map[Key]struct{}{}
+		}
+		ix.postings[w][d.Key] = struct{}{}
+	}
+}
+
+// Remove drops the document with the given key, if any.
+func (ix *Index) Remove(key Key) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(key)
+}
+
+func (ix *Index) remove(key Key) {
+	p, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	delete(ix.docs, key)
+	for _, w := range p.words() {
+		delete(ix.postings[w], key)
+		if len(ix.postings[w]) == 0 {
+			delete(ix.postings, w)
+		}
+	}
+}
+
+// Search returns the documents matching q, best first.
+func (ix *Index) Search(q Query) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var hits []Hit
+	for _, p := range ix.candidates(q) {
+		if score := q.score(p); score > 0 {
+			hits = append(hits, Hit{Document: p.doc, Score: score})
+		}
+	}
+	sortHits(hits)
+	return hits
+}
+
+// candidates returns the documents holding, for every word of q, a word
+// it matches. ix.mu must be held.
+func (ix *Index) candidates(q Query) []*prepared {
+	if len(q.tokens) == 0 {
+		l := make([]*prepared, 0, len(ix.docs))
+		for _, p := range ix.docs {
+			l = append(l, p)
+		}
+		return l
+	}
+
+	var keys map[Key]struct{}
+	for _, t := range q.tokens {
+		matched := map[Key]struct{}{}
+		for w, docs := range ix.postings {
+			if MatchToken(t, w) == 0 {
+				continue
+			}
+			for k := range docs {
+				if keys == nil {
+					matched[k] = struct{}{}
+				} else if _, ok := keys[k]; ok {
+					matched[k] = struct{}{}
+				}
+			}
+		}
+		keys = matched
+		if len(keys) == 0 {
+			return nil
+		}
+	}
+
+	l := make([]*prepared, 0, len(keys))
+	for k := range keys {
+		l = append(l, ix.docs[k])
+	}
+	return l
+}
+
+// sortHits orders hits by decreasing score, then by title, kind and ID.
+func sortHits(hits []Hit) {
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Title.Text != b.Title.Text {
+			return a.Title.Text < b.Title.Text
+		}
+		if a.Key.Kind != b.Key.Kind {
+			return a.Key.Kind < b.Key.Kind
+		}
+		return a.Key.ID < b.Key.ID
+	})
+}
@@ -0,0 +1,94 @@
+package search
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var words = strings.Fields(`luke leia han chewbacca vader yoda obiwan lando
+	tatooine hoth endor dagobah bespin naboo coruscant kashyyyk falcon
+	xwing tie destroyer rebel empire jedi sith droid wookiee smuggler
+	pilot princess general senator farmboy bounty hunter moisture farmer`)
+
+// corpus returns n documents made of random words, the same ones for the
+// same n.
+func corpus(n int) []Document {
+	rnd := rand.New(rand.NewSource(int64(n)))
+	text := func(k int) string {
+		l := make([]string, k)
+		for i := range l {
+			l[i] = words[rnd.Intn(len(words))]
+		}
+		return strings.Join(l, " ")
+	}
+
+	docs := make([]Document, n)
+	for i := range docs {
+		docs[i] = Document{
+			Key:   Key{Kind: "Review", ID: strconv.Itoa(i)},
+			Title: Field{Name: "name", Text: text(2)},
+			Body:  []Field{{Name: "commentary", Text: text(12)}},
+		}
+	}
+	return docs
+}
+
+// linearScan scores every document, as searching without an index does.
+func linearScan(docs []Document, q Query) []Hit {
+	var hits []Hit
+	for _, d := range docs {
+		if score := q.Score(d); score > 0 {
+			hits = append(hits, Hit{Document: d, Score: score})
+		}
+	}
+	sortHits(hits)
+	return hits
+}
+
+func newIndex(docs []Document) *Index {
+	ix := NewIndex()
+	for _, d := range docs {
+		ix.Put(d)
+	}
+	return ix
+}
+
+var queries = []string{"vader", "jedi pilot", "wooki", "tatooin farmer", "nothing matches"}
+
+func TestIndexMatchesLinearScan(t *testing.T) {
+	docs := corpus(500)
+	ix := newIndex(docs)
+	for _, text := range append(queries, "") {
+		q := NewQuery(text)
+		if got, want := ix.Search(q), linearScan(docs, q); !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q) found %d hits, want the %d of a linear scan", text, len(got), len(want))
+		}
+	}
+}
+
+func BenchmarkIndexSearch(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		docs := corpus(n)
+		ix := newIndex(docs)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ix.Search(NewQuery(queries[i%len(queries)]))
+			}
+		})
+	}
+}
+
+func BenchmarkLinearScan(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		docs := corpus(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearScan(docs, NewQuery(queries[i%len(queries)]))
+			}
+		})
+	}
+}
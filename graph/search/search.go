@@ -0,0 +1,221 @@
+// Package search matches free text typed by users against the names and
+// descriptions of the dataset. Matching ignores case and diacritics,
+// tolerates small typos and scores how relevant each match is.
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/agnivade/levenshtein"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Scores of a query token depending on how it matches a text token.
+const (
+	scoreExact  = 10
+	scorePrefix = 6
+	scoreInside = 4
+	scoreFuzzy  = 2
+
+	// scorePhrase rewards texts holding the whole query as typed.
+	scorePhrase = 5
+	// scoreWhole rewards titles equal to the query.
+	scoreWhole = 20
+
+	// titleWeight multiplies the scores of the words of a title.
+	titleWeight = 2
+)
+
+// Normalize folds s to lower case without diacritics, so that "Padmé"
+// and "PADME" compare equal.
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// Tokens splits the normalized form of s into words.
+func Tokens(s string) []string {
+	return strings.FieldsFunc(Normalize(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Key identifies a document, such as a human by its ID.
+type Key struct {
+	Kind string
+	ID   string
+}
+
+// Field is a named text of a document, such as the commentary of a review.
+type Field struct {
+	Name string
+	Text string
+}
+
+// Document is the searchable text of an entity.
+type Document struct {
+	Key Key
+	// Title is the name of the entity. Matches in it weigh more, and it
+	// orders documents scoring the same.
+	Title Field
+	// Body holds the other texts describing the entity.
+	Body []Field
+}
+
+// prepared is a document broken down into normalized words.
+type prepared struct {
+	doc   Document
+	title []string
+	body  [][]string
+	// phrases holds the words of the title, then of each body text,
+	// joined by single spaces.
+	phrases []string
+}
+
+func prepare(d Document) *prepared {
+	p := &prepared{
+		doc:     d,
+		title:   Tokens(d.Title.Text),
+		body:    make([][]string, len(d.Body)),
+		phrases: make([]string, 0, 1+len(d.Body)),
+	}
+	p.phrases = append(p.phrases, strings.Join(p.title, " "))
+	for i, f := range d.Body {
+		p.body[i] = Tokens(f.Text)
+		p.phrases = append(p.phrases, strings.Join(p.body[i], " "))
+	}
+	return p
+}
+
+// words returns every distinct word of the document.
+func (p *prepared) words() []string {
+	seen := map[string]bool{}
+	var l []string
+	for _, field := range append([][]string{p.title}, p.body...) {
+		for _, w := range field {
+			if !seen[w] {
+				seen[w] = true
+				l = append(l, w)
+			}
+		}
+	}
+	return l
+}
+
+// holds reports whether a text of the document contains phrase.
+func (p *prepared) holds(phrase string) bool {
+	for _, text := range p.phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query is a parsed search text.
+type Query struct {
+	phrase string
+	tokens []string
+}
+
+// NewQuery parses text.
+func NewQuery(text string) Query {
+	tokens := Tokens(text)
+	return Query{
+		phrase: strings.Join(tokens, " "),
+		tokens: tokens,
+	}
+}
+
+// Tokens returns the words of the query.
+func (q Query) Tokens() []string {
+	return q.tokens
+}
+
+// Score rates how well d matches the query; zero means it does not match.
+// Every word of the query must match a word of d, either exactly, as a
+// prefix, inside it or within a couple of typos, matches in the title
+// weighing twice as much as matches in the body. An empty query matches
+// everything with the lowest score.
+func (q Query) Score(d Document) int {
+	return q.score(prepare(d))
+}
+
+func (q Query) score(p *prepared) int {
+	if len(q.tokens) == 0 {
+		return 1
+	}
+
+	total := 0
+	for _, t := range q.tokens {
+		best := 0
+		for _, w := range p.title {
+			if score := titleWeight * MatchToken(t, w); score > best {
+				best = score
+			}
+		}
+		for _, field := range p.body {
+			for _, w := range field {
+				if score := MatchToken(t, w); score > best {
+					best = score
+				}
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	switch {
+	case p.phrases[0] == q.phrase:
+		total += scoreWhole
+	case len(q.tokens) > 1 && p.holds(q.phrase):
+		total += scorePhrase
+	}
+	return total
+}
+
+// MatchToken rates how well the query token t matches the word w, both
+// normalized; zero means it does not match.
+func MatchToken(t, w string) int {
+	switch {
+	case t == w:
+		return scoreExact
+	case strings.HasPrefix(w, t):
+		return scorePrefix
+	case strings.Contains(w, t):
+		return scoreInside
+	}
+	edits := maxEdits(t)
+	if edits == 0 {
+		return 0
+	}
+	if d := len([]rune(t)) - len([]rune(w)); d > edits || -d > edits {
+		return 0
+	}
+	if levenshtein.ComputeDistance(t, w) <= edits {
+		return scoreFuzzy
+	}
+	return 0
+}
+
+// maxEdits is the number of typos tolerated in a query token; short tokens
+// must be spelled right or they would match almost anything.
+func maxEdits(t string) int {
+	switch n := len([]rune(t)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,347 @@
+package store
+
+import (
+	"time"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+)
+
+// Dataset is a snapshot of the entities a store is seeded with.
+type Dataset struct {
+	Humans    []model.Human
+	Droids    []model.Droid
+	Starships []model.Starship
+	Vehicles  []model.Vehicle
+	Films     []model.Film
+	Planets   []model.Planet
+	Species   []model.Species
+}
+
+// Fixtures returns the sample data of the saga.
+// Every call builds a fresh copy, so callers are free to modify it.
+func Fixtures() Dataset {
+	return Dataset{
+		Humans: []model.Human{
+			{
+				CharacterFields: model.CharacterFields{
+					ID:          "1000",
+					Name:        "Luke Skywalker",
+					FriendIds:   []string{"1002", "1003", "2000", "2001"},
+					AppearsIn:   []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi, model.EpisodeAwakens, model.EpisodeLastjedi, model.EpisodeSkywalker},
+					StarshipIds: []string{"3001", "3003"},
+					VehicleIds:  []string{"6000", "6002"},
+				},
+				HeightMeters: 1.72,
+				Mass:         77,
+				HomeworldID:  "4000",
+				SpeciesID:    "5000",
+			},
+			{
+				CharacterFields: model.CharacterFields{
+					ID:          "1001",
+					Name:        "Darth Vader",
+					FriendIds:   []string{"1004"},
+					AppearsIn:   []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi, model.EpisodeSith},
+					StarshipIds: []string{"3002"},
+				},
+				HeightMeters: 2.02,
+				Mass:         136,
+				HomeworldID:  "4000",
+				SpeciesID:    "5000",
+			},
+			{
+				CharacterFields: model.CharacterFields{
+					ID:          "1002",
+					Name:        "Han Solo",
+					FriendIds:   []string{"1000", "1003", "2001"},
+					AppearsIn:   []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi, model.EpisodeAwakens},
+					StarshipIds: []string{"3000", "3003"},
+				},
+				HeightMeters: 1.8,
+				Mass:         80,
+				HomeworldID:  "4002",
+				SpeciesID:    "5000",
+			},
+			{
+				CharacterFields: model.CharacterFields{
+					ID:         "1003",
+					Name:       "Leia Organa",
+					FriendIds:  []string{"1000", "1002", "2000", "2001"},
+					AppearsIn:  []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi, model.EpisodeAwakens, model.EpisodeLastjedi, model.EpisodeSkywalker},
+					VehicleIds: []string{"6002"},
+				},
+				HeightMeters: 1.5,
+				Mass:         49,
+				HomeworldID:  "4001",
+				SpeciesID:    "5000",
+			},
+			{
+				CharacterFields: model.CharacterFields{
+					ID:        "1004",
+					Name:      "Wilhuff Tarkin",
+					FriendIds: []string{"1001"},
+					AppearsIn: []model.Episode{model.EpisodeNewhope, model.EpisodeSith},
+				},
+				HeightMeters: 1.8,
+				Mass:         0,
+				HomeworldID:  "4003",
+				SpeciesID:    "5000",
+			},
+		},
+		Droids: []model.Droid{
+			{
+				CharacterFields: model.CharacterFields{
+					ID:        "2000",
+					Name:      "C-3PO",
+					FriendIds: []string{"1000", "1002", "1003", "2001"},
+					AppearsIn: []model.Episode{
+						model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi,
+						model.EpisodePhantom, model.EpisodeClones, model.EpisodeSith,
+						model.EpisodeAwakens, model.EpisodeLastjedi, model.EpisodeSkywalker,
+					},
+				},
+				PrimaryFunction: "Protocol",
+			},
+			{
+				CharacterFields: model.CharacterFields{
+					ID:        "2001",
+					Name:      "R2-D2",
+					FriendIds: []string{"1000", "1002", "1003"},
+					AppearsIn: []model.Episode{
+						model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi,
+						model.EpisodePhantom, model.EpisodeClones, model.EpisodeSith,
+						model.EpisodeAwakens, model.EpisodeLastjedi, model.EpisodeSkywalker,
+					},
+					StarshipIds: []string{"3001"},
+				},
+				PrimaryFunction: "Astromech",
+			},
+		},
+		Starships: []model.Starship{
+			{
+				ID:   "3000",
+				Name: "Millennium Falcon",
+				History: []model.HistoryPoint{
+					point(1, 2, "1977-05-25T08:00:00Z", "Mos Eisley"),
+					point(4, 5, "1977-05-26T14:30:00Z", "Alderaan"),
+					point(1, 2, "1977-05-28T09:15:00Z", "Mos Eisley"),
+					point(3, 2, "1977-05-29T18:00:00Z", "Yavin 4"),
+				},
+				Length: 34.37,
+			},
+			{
+				ID:   "3001",
+				Name: "X-Wing",
+				History: []model.HistoryPoint{
+					point(6, 4, "1977-05-29T20:00:00Z", "Death Star"),
+					point(3, 2, "1977-05-30T07:45:00Z", "Yavin 4"),
+					point(2, 3, "1980-05-21T11:00:00Z", "Dagobah"),
+					point(5, 1, "1980-05-23T16:20:00Z", "Hoth"),
+				},
+				Length: 12.5,
+			},
+			{
+				ID:   "3002",
+				Name: "TIE Advanced x1",
+				History: []model.HistoryPoint{
+					point(3, 2, "1977-05-29T19:10:00Z", "Yavin 4"),
+					point(7, 2, "1977-05-30T02:00:00Z", "Bespin"),
+					point(6, 4, "1977-05-31T12:00:00Z", "Death Star"),
+					point(3, 2, "1977-06-01T09:30:00Z", "Yavin 4"),
+				},
+				Length: 9.2,
+			},
+			{
+				ID:   "3003",
+				Name: "Imperial shuttle",
+				History: []model.HistoryPoint{
+					point(1, 7, "1983-05-25T06:00:00Z", "Coruscant"),
+					point(3, 5, "1983-05-25T13:40:00Z", "Kuat"),
+					point(5, 3, "1983-05-26T08:00:00Z", "Sullust"),
+					point(7, 1, "1983-05-27T10:30:00Z", "Endor"),
+				},
+				Length: 20,
+			},
+		},
+		Vehicles: []model.Vehicle{
+			{ID: "6000", Name: "Snowspeeder", Model: "t-47 airspeeder", VehicleClass: "airspeeder", Length: 5.3},
+			{ID: "6001", Name: "AT-AT", Model: "All Terrain Armored Transport", VehicleClass: "walker", Length: 20},
+			{ID: "6002", Name: "Speeder bike", Model: "74-Z speeder bike", VehicleClass: "speeder", Length: 3},
+			{ID: "6003", Name: "Sand Crawler", Model: "Digger Crawler", VehicleClass: "wheeled", Length: 36.8},
+		},
+		Films: []model.Film{
+			{
+				Episode:       model.EpisodeNewhope,
+				Title:         "A New Hope",
+				EpisodeNumber: 4,
+				ReleaseDate:   time.Date(1977, time.May, 25, 0, 0, 0, 0, time.UTC),
+				Director:      "George Lucas",
+				OpeningCrawl: "It is a period of civil war. Rebel spaceships, striking from a hidden base, " +
+					"have won their first victory against the evil Galactic Empire.\n\n" +
+					"During the battle, Rebel spies managed to steal secret plans to the Empire's " +
+					"ultimate weapon, the DEATH STAR, an armored space station with enough power " +
+					"to destroy an entire planet.\n\n" +
+					"Pursued by the Empire's sinister agents, Princess Leia races home aboard her " +
+					"starship, custodian of the stolen plans that can save her people and restore " +
+					"freedom to the galaxy....",
+				HeroIds: []string{"2001", "1000", "1002", "1003"},
+			},
+			{
+				Episode:       model.EpisodeEmpire,
+				Title:         "The Empire Strikes Back",
+				EpisodeNumber: 5,
+				ReleaseDate:   time.Date(1980, time.May, 21, 0, 0, 0, 0, time.UTC),
+				Director:      "Irvin Kershner",
+				OpeningCrawl: "It is a dark time for the Rebellion. Although the Death Star has been " +
+					"destroyed, Imperial troops have driven the Rebel forces from their hidden base " +
+					"and pursued them across the galaxy.\n\n" +
+					"Evading the dreaded Imperial Starfleet, a group of freedom fighters led by " +
+					"Luke Skywalker has established a new secret base on the remote ice world of " +
+					"Hoth.\n\n" +
+					"The evil lord Darth Vader, obsessed with finding young Skywalker, has " +
+					"dispatched thousands of remote probes into the far reaches of space....",
+				HeroIds: []string{"1000", "1002", "1003"},
+			},
+			{
+				Episode:       model.EpisodeJedi,
+				Title:         "Return of the Jedi",
+				EpisodeNumber: 6,
+				ReleaseDate:   time.Date(1983, time.May, 25, 0, 0, 0, 0, time.UTC),
+				Director:      "Richard Marquand",
+				OpeningCrawl: "Luke Skywalker has returned to his home planet of Tatooine in an attempt " +
+					"to rescue his friend Han Solo from the clutches of the vile gangster Jabba " +
+					"the Hutt.\n\n" +
+					"Little does Luke know that the GALACTIC EMPIRE has secretly begun construction " +
+					"on a new armored space station even more powerful than the first dreaded " +
+					"Death Star.\n\n" +
+					"When completed, this ultimate weapon will spell certain doom for the small " +
+					"band of rebels struggling to restore freedom to the galaxy...",
+				HeroIds: []string{"2001", "1000", "1003"},
+			},
+			{
+				Episode:       model.EpisodePhantom,
+				Title:         "The Phantom Menace",
+				EpisodeNumber: 1,
+				ReleaseDate:   time.Date(1999, time.May, 19, 0, 0, 0, 0, time.UTC),
+				Director:      "George Lucas",
+				OpeningCrawl: "Turmoil has engulfed the Galactic Republic. The taxation of trade routes to " +
+					"outlying star systems is in dispute.\n\n" +
+					"Hoping to resolve the matter with a blockade of deadly battleships, the greedy " +
+					"Trade Federation has stopped all shipping to the small planet of Naboo.\n\n" +
+					"While the Congress of the Republic endlessly debates this alarming chain of " +
+					"events, the Supreme Chancellor has secretly dispatched two Jedi Knights, the " +
+					"guardians of peace and justice in the galaxy, to settle the conflict....",
+				HeroIds: []string{"2001", "2000"},
+			},
+			{
+				Episode:       model.EpisodeClones,
+				Title:         "Attack of the Clones",
+				EpisodeNumber: 2,
+				ReleaseDate:   time.Date(2002, time.May, 16, 0, 0, 0, 0, time.UTC),
+				Director:      "George Lucas",
+				OpeningCrawl: "There is unrest in the Galactic Senate. Several thousand solar systems have " +
+					"declared their intentions to leave the Republic.\n\n" +
+					"This separatist movement, under the leadership of the mysterious Count Dooku, " +
+					"has made it difficult for the limited number of Jedi Knights to maintain peace " +
+					"and order in the galaxy.\n\n" +
+					"Senator Amidala, the former Queen of Naboo, is returning to the Galactic " +
+					"Senate to vote on the critical issue of creating an ARMY OF THE REPUBLIC to " +
+					"assist the overwhelmed Jedi....",
+				HeroIds: []string{"2001", "2000"},
+			},
+			{
+				Episode:       model.EpisodeSith,
+				Title:         "Revenge of the Sith",
+				EpisodeNumber: 3,
+				ReleaseDate:   time.Date(2005, time.May, 19, 0, 0, 0, 0, time.UTC),
+				Director:      "George Lucas",
+				OpeningCrawl: "War! The Republic is crumbling under attacks by the ruthless Sith Lord, Count " +
+					"Dooku. There are heroes on both sides. Evil is everywhere.\n\n" +
+					"In a stunning move, the fiendish droid leader, General Grievous, has swept " +
+					"into the Republic capital and kidnapped Chancellor Palpatine, leader of the " +
+					"Galactic Senate.\n\n" +
+					"As the Separatist Droid Army attempts to flee the besieged capital with their " +
+					"valuable hostage, two Jedi Knights lead a desperate mission to rescue the " +
+					"captive Chancellor....",
+				HeroIds: []string{"1001", "2001", "2000"},
+			},
+			{
+				Episode:       model.EpisodeAwakens,
+				Title:         "The Force Awakens",
+				EpisodeNumber: 7,
+				ReleaseDate:   time.Date(2015, time.December, 18, 0, 0, 0, 0, time.UTC),
+				Director:      "J. J. Abrams",
+				OpeningCrawl: "Luke Skywalker has vanished. In his absence, the sinister FIRST ORDER has " +
+					"risen from the ashes of the Empire and will not rest until Skywalker, the last " +
+					"Jedi, has been destroyed.\n\n" +
+					"With the support of the REPUBLIC, General Leia Organa leads a brave " +
+					"RESISTANCE. She is desperate to find her brother Luke and gain his help in " +
+					"restoring peace and justice to the galaxy.\n\n" +
+					"Leia has sent her most daring pilot on a secret mission to Jakku, where an old " +
+					"ally has discovered a clue to Luke's whereabouts....",
+				HeroIds: []string{"1002", "1003", "2001"},
+			},
+			{
+				Episode:       model.EpisodeLastjedi,
+				Title:         "The Last Jedi",
+				EpisodeNumber: 8,
+				ReleaseDate:   time.Date(2017, time.December, 15, 0, 0, 0, 0, time.UTC),
+				Director:      "Rian Johnson",
+				OpeningCrawl: "The FIRST ORDER reigns. Having decimated the peaceful Republic, Supreme Leader " +
+					"Snoke now deploys his merciless legions to seize military control of the " +
+					"galaxy.\n\n" +
+					"Only General Leia Organa's band of RESISTANCE fighters stand against the " +
+					"rising tyranny, certain that Jedi Master Luke Skywalker will return and " +
+					"restore a spark of hope to the fight.\n\n" +
+					"But the Resistance has been exposed. As the First Order speeds toward the " +
+					"rebel base, the brave heroes mount a desperate escape....",
+				HeroIds: []string{"1000", "1003", "2001"},
+			},
+			{
+				Episode:       model.EpisodeSkywalker,
+				Title:         "The Rise of Skywalker",
+				EpisodeNumber: 9,
+				ReleaseDate:   time.Date(2019, time.December, 20, 0, 0, 0, 0, time.UTC),
+				Director:      "J. J. Abrams",
+				OpeningCrawl: "The dead speak! The galaxy has heard a mysterious broadcast, a threat of " +
+					"REVENGE in the sinister voice of the late EMPEROR PALPATINE.\n\n" +
+					"GENERAL LEIA ORGANA dispatches secret agents to gather intelligence, while " +
+					"REY, the last hope of the Jedi, trains for battle against the diabolical FIRST " +
+					"ORDER.\n\n" +
+					"Meanwhile, Supreme Leader KYLO REN rages in search of the phantom Emperor, " +
+					"determined to destroy any threat to his power....",
+				HeroIds: []string{"1003", "1000", "2000"},
+			},
+		},
+		Planets: []model.Planet{
+			{ID: "4000", Name: "Tatooine", Climate: "arid", Terrain: "desert", Population: population(200000)},
+			{ID: "4001", Name: "Alderaan", Climate: "temperate", Terrain: "grasslands, mountains", Population: population(2000000000)},
+			{ID: "4002", Name: "Corellia", Climate: "temperate", Terrain: "plains, urban, hills, forests", Population: population(3000000000)},
+			{ID: "4003", Name: "Eriadu", Climate: "polluted", Terrain: "cityscape", Population: population(22000000000)},
+			{ID: "4004", Name: "Hoth", Climate: "frozen", Terrain: "tundra, ice caves, mountain ranges"},
+		},
+		Species: []model.Species{
+			{ID: "5000", Name: "Human", Classification: "mammal", Language: "Galactic Basic"},
+			{ID: "5001", Name: "Wookiee", Classification: "mammal", Language: "Shyriiwook"},
+			{ID: "5002", Name: "Hutt", Classification: "gastropod", Language: "Huttese"},
+		},
+	}
+}
+
+func population(n float64) *float64 {
+	return &n
+}
+
+// point returns a position of a starship on the galaxy map, at a time
+// given in RFC 3339.
+func point(x, y int, at, locationName string) model.HistoryPoint {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		panic(err)
+	}
+	return model.HistoryPoint{
+		Coordinate:   model.Coordinate{X: x, Y: y},
+		Timestamp:    &t,
+		LocationName: &locationName,
+	}
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Every kind of entity owns a block of a thousand numeric IDs.
+const (
+	HumanIDBase    = 1000
+	DroidIDBase    = 2000
+	StarshipIDBase = 3000
+	PlanetIDBase   = 4000
+	SpeciesIDBase  = 5000
+	VehicleIDBase  = 6000
+
+	idRangeSize = 1000
+)
+
+// ErrIDRangeExhausted is returned when a block has no ID left to hand out.
+var ErrIDRangeExhausted = errors.New("no IDs left in range")
+
+// NextID returns the ID following the highest of ids that falls in the
+// block starting at base, or base itself when the block is still empty.
+func NextID(base int, ids []string) (string, error) {
+	next := base
+	for _, id := range ids {
+		n, err := strconv.Atoi(id)
+		if err != nil || n < base || n >= base+idRangeSize {
+			continue
+		}
+		if n >= next {
+			next = n + 1
+		}
+	}
+	if next >= base+idRangeSize {
+		return "", ErrIDRangeExhausted
+	}
+	return strconv.Itoa(next), nil
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"math"
+	"sort"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+)
+
+// cellSize is the side, in map units, of the square cells of a grid.
+const cellSize = 4
+
+type cell struct{ x, y int }
+
+// grid indexes points of the map plane by the cell holding them, so that
+// the points of a region are found by visiting the cells it overlaps.
+type grid struct {
+	points map[string]model.Coordinate
+	cells  map[cell]map[string]struct{}
+}
+
+func newGrid() *grid {
+	return &grid{
+		points: map[string]model.Coordinate{},
+		cells:  map[cell]map[string]struct{}{},
+	}
+}
+
+// put places id at p, moving it when it was placed before.
+func (g *grid) put(id string, p model.Coordinate) {
+	g.remove(id)
+	c := cellOf(p.X, p.Y)
+	if g.cells[c] == nil {
+		g.cells[c] = map[string]struct{}{}
+	}
+	g.cells[c][id] = struct{}{}
+	g.points[id] = p
+}
+
+func (g *grid) remove(id string) {
+	p, ok := g.points[id]
+	if !ok {
+		return
+	}
+	c := cellOf(p.X, p.Y)
+	delete(g.cells[c], id)
+	if len(g.cells[c]) == 0 {
+		delete(g.cells, c)
+	}
+	delete(g.points, id)
+}
+
+// inBox returns the IDs placed in b, ordered by ID.
+func (g *grid) inBox(b model.BoundingBox) []string {
+	var l []string
+	g.visit(b, func(id string, p model.Coordinate) {
+		if p.X >= b.MinX && p.X <= b.MaxX && p.Y >= b.MinY && p.Y <= b.MaxY {
+			l = append(l, id)
+		}
+	})
+	sort.Strings(l)
+	return l
+}
+
+// near returns the IDs placed within radius of x, y, nearest first and
+// then by ID.
+func (g *grid) near(x, y int, radius float64) []string {
+	type hit struct {
+		id   string
+		dist float64
+	}
+	var hits []hit
+	g.visit(model.BoxAround(x, y, radius), func(id string, p model.Coordinate) {
+		if d := math.Hypot(float64(p.X)-float64(x), float64(p.Y)-float64(y)); d <= radius {
+			hits = append(hits, hit{id, d})
+		}
+	})
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].dist != hits[j].dist {
+			return hits[i].dist < hits[j].dist
+		}
+		return hits[i].id < hits[j].id
+	})
+
+	l := make([]string, len(hits))
+	for i, h := range hits {
+		l[i] = h.id
+	}
+	return l
+}
+
+// visit calls fn for every point of the cells b overlaps. Boxes covering
+// more cells than are occupied walk the occupied cells instead.
+func (g *grid) visit(b model.BoundingBox, fn func(id string, p model.Coordinate)) {
+	lo, hi := cellOf(b.MinX, b.MinY), cellOf(b.MaxX, b.MaxY)
+	visitCell := func(c cell) {
+		for id := range g.cells[c] {
+			fn(id, g.points[id])
+		}
+	}
+	if n := float64(hi.x-lo.x+1) * float64(hi.y-lo.y+1); n > float64(len(g.cells)) {
+		for c := range g.cells {
+			if c.x >= lo.x && c.x <= hi.x && c.y >= lo.y && c.y <= hi.y {
+				visitCell(c)
+			}
+		}
+		return
+	}
+	for cx := lo.x; cx <= hi.x; cx++ {
+		for cy := lo.y; cy <= hi.y; cy++ {
+			visitCell(cell{cx, cy})
+		}
+	}
+}
+
+// cellOf returns the cell holding x, y, rounding towards negative
+// infinity so that cells keep the same size across the axes.
+func cellOf(x, y int) cell {
+	return cell{floorDiv(x, cellSize), floorDiv(y, cellSize)}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
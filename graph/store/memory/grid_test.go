@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+)
+
+// newTestGrid places points on both sides of the axes and of cell borders.
+func newTestGrid() *grid {
+	g := newGrid()
+	for id, p := range map[string]model.Coordinate{
+		"a": {X: 0, Y: 0},
+		"b": {X: 3, Y: 0},
+		"c": {X: 4, Y: 0},
+		"d": {X: -1, Y: -1},
+		"e": {X: -5, Y: 2},
+		"f": {X: 100, Y: -100},
+		"g": {X: math.MaxInt32, Y: math.MinInt32},
+	} {
+		g.put(id, p)
+	}
+	return g
+}
+
+func TestGridInBox(t *testing.T) {
+	tests := []struct {
+		name string
+		box  model.BoundingBox
+		want []string
+	}{
+		{name: "single point", box: model.BoundingBox{MinX: 0, MinY: 0, MaxX: 0, MaxY: 0}, want: []string{"a"}},
+		{name: "corners included", box: model.BoundingBox{MinX: 0, MinY: 0, MaxX: 4, MaxY: 0}, want: []string{"a", "b", "c"}},
+		{name: "across the axes", box: model.BoundingBox{MinX: -5, MinY: -1, MaxX: 0, MaxY: 2}, want: []string{"a", "d", "e"}},
+		{name: "empty", box: model.BoundingBox{MinX: 10, MinY: 10, MaxX: 20, MaxY: 20}},
+		{name: "inverted", box: model.BoundingBox{MinX: 4, MinY: 0, MaxX: 0, MaxY: 0}},
+		{name: "whole plane", box: model.BoundingBox{MinX: math.MinInt, MinY: math.MinInt, MaxX: math.MaxInt, MaxY: math.MaxInt},
+			want: []string{"a", "b", "c", "d", "e", "f", "g"}},
+	}
+	g := newTestGrid()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.inBox(tt.box); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inBox(%+v) = %v, want %v", tt.box, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGridNear(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   int
+		radius float64
+		want   []string
+	}{
+		{name: "zero radius", x: 0, y: 0, radius: 0, want: []string{"a"}},
+		{name: "nearest first", x: 0, y: 0, radius: 4, want: []string{"a", "d", "b", "c"}},
+		{name: "edge of the disc", x: 0, y: 0, radius: 3, want: []string{"a", "d", "b"}},
+		{name: "ties by ID", x: 2, y: 0, radius: 2, want: []string{"b", "a", "c"}},
+		{name: "across a cell border", x: -4, y: 1, radius: 1.5, want: []string{"e"}},
+		{name: "nothing", x: 50, y: 50, radius: 10, want: []string{}},
+		{name: "huge radius", x: 0, y: 0, radius: math.MaxFloat64, want: []string{"a", "d", "b", "c", "e", "f", "g"}},
+		{name: "infinite radius", x: 0, y: 0, radius: math.Inf(1), want: []string{"a", "d", "b", "c", "e", "f", "g"}},
+		{name: "far center", x: 1 << 40, y: 0, radius: 1e30, want: []string{"g", "f", "c", "b", "a", "d", "e"}},
+	}
+	g := newTestGrid()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.near(tt.x, tt.y, tt.radius); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("near(%d, %d, %g) = %v, want %v", tt.x, tt.y, tt.radius, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGridMove(t *testing.T) {
+	g := newTestGrid()
+	g.put("a", model.Coordinate{X: 50, Y: 50})
+	g.remove("b")
+
+	if got, want := g.near(0, 0, 4), []string{"d", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("near(0, 0, 4) = %v, want %v", got, want)
+	}
+	if got, want := g.inBox(model.BoundingBox{MinX: 50, MinY: 50, MaxX: 50, MaxY: 50}), []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("inBox() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,731 @@
+// Package memory is the in-memory implementation of the store repositories.
+// Its content lives only as long as the process does.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+)
+
+// Store keeps every entity in plain maps keyed by ID.
+// It is safe for concurrent use.
+type Store struct {
+	mu        sync.RWMutex
+	humans    map[string]model.Human
+	droids    map[string]model.Droid
+	starships map[string]model.Starship
+	vehicles  map[string]model.Vehicle
+	reviews   map[model.Episode][]*model.Review
+	films     map[model.Episode]model.Film
+	planets   map[string]model.Planet
+	species   map[string]model.Species
+
+	// reviewsByID indexes the reviews of every episode.
+	reviewsByID  map[string]*model.Review
+	lastReviewID int
+	// pilots and vehiclePilots index the characters of every starship and
+	// vehicle, in ID order. They follow the StarshipIds and VehicleIds of
+	// the characters.
+	pilots        map[string][]string
+	vehiclePilots map[string][]string
+	// positions indexes the last known position of every starship.
+	positions *grid
+}
+
+// New returns a store seeded with the given data.
+func New(data store.Dataset) *Store {
+	s := &Store{
+		humans:    map[string]model.Human{},
+		droids:    map[string]model.Droid{},
+		starships: map[string]model.Starship{},
+		vehicles:  map[string]model.Vehicle{},
+		reviews:   map[model.Episode][]*model.Review{},
+		films:     map[model.Episode]model.Film{},
+		planets:   map[string]model.Planet{},
+		species:   map[string]model.Species{},
+
+		reviewsByID:   map[string]*model.Review{},
+		pilots:        map[string][]string{},
+		vehiclePilots: map[string][]string{},
+		positions:     newGrid(),
+	}
+	for _, h := range data.Humans {
+		s.humans[h.ID] = h
+		s.indexPilot(h.CharacterFields)
+	}
+	for _, d := range data.Droids {
+		s.droids[d.ID] = d
+		s.indexPilot(d.CharacterFields)
+	}
+	for _, sh := range data.Starships {
+		s.putStarship(sh)
+	}
+	for _, v := range data.Vehicles {
+		s.vehicles[v.ID] = v
+	}
+	for _, f := range data.Films {
+		s.films[f.Episode] = f
+	}
+	for _, p := range data.Planets {
+		s.planets[p.ID] = p
+	}
+	for _, sp := range data.Species {
+		s.species[sp.ID] = sp
+	}
+	return s
+}
+
+// Repository exposes the store through the store interfaces.
+func (s *Store) Repository() store.Repository {
+	return store.Repository{
+		Characters: s,
+		Starships:  s,
+		Vehicles:   s,
+		Reviews:    s,
+		Films:      s,
+		Planets:    s,
+		Species:    s,
+	}
+}
+
+func (s *Store) Human(_ context.Context, id string) (*model.Human, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if h, ok := s.humans[id]; ok {
+		return &h, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) Droid(_ context.Context, id string) (*model.Droid, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if d, ok := s.droids[id]; ok {
+		return &d, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) Humans(_ context.Context) ([]*model.Human, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Human, 0, len(s.humans))
+	for _, h := range s.humans {
+		h := h
+		l = append(l, &h)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l, nil
+}
+
+func (s *Store) Droids(_ context.Context) ([]*model.Droid, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Droid, 0, len(s.droids))
+	for _, d := range s.droids {
+		d := d
+		l = append(l, &d)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l, nil
+}
+
+func (s *Store) CharactersByIDs(_ context.Context, ids []string) ([]model.Character, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]model.Character, len(ids))
+	for i, id := range ids {
+		if h, ok := s.humans[id]; ok {
+			l[i] = &h
+		} else if d, ok := s.droids[id]; ok {
+			l[i] = &d
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) Starship(_ context.Context, id string) (*model.Starship, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sh, ok := s.starships[id]; ok {
+		return &sh, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) Starships(_ context.Context) ([]*model.Starship, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Starship, 0, len(s.starships))
+	for _, sh := range s.starships {
+		sh := sh
+		l = append(l, &sh)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l, nil
+}
+
+func (s *Store) StarshipsByIDs(_ context.Context, ids []string) ([]*model.Starship, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Starship, len(ids))
+	for i, id := range ids {
+		if sh, ok := s.starships[id]; ok {
+			l[i] = &sh
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) StarshipPilotIDs(_ context.Context, ids []string) ([][]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return lookupAll(s.pilots, ids), nil
+}
+
+func (s *Store) StarshipsNear(_ context.Context, x, y int, radius float64) ([]*model.Starship, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.starshipsOf(s.positions.near(x, y, radius)), nil
+}
+
+func (s *Store) StarshipsInRegion(_ context.Context, box model.BoundingBox) ([]*model.Starship, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.starshipsOf(s.positions.inBox(box)), nil
+}
+
+// starshipsOf returns copies of the starships of ids, in the same order.
+func (s *Store) starshipsOf(ids []string) []*model.Starship {
+	l := make([]*model.Starship, len(ids))
+	for i, id := range ids {
+		sh := s.starships[id]
+		l[i] = &sh
+	}
+	return l
+}
+
+func (s *Store) CreateHuman(_ context.Context, h *model.Human) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := store.NextID(store.HumanIDBase, keys(s.humans))
+	if err != nil {
+		return err
+	}
+	h.ID = id
+	s.humans[id] = *h
+	s.indexPilot(h.CharacterFields)
+	s.mirrorFriends(id, nil, h.FriendIds)
+	return nil
+}
+
+func (s *Store) CreateDroid(_ context.Context, d *model.Droid) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := store.NextID(store.DroidIDBase, keys(s.droids))
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	s.droids[id] = *d
+	s.indexPilot(d.CharacterFields)
+	s.mirrorFriends(id, nil, d.FriendIds)
+	return nil
+}
+
+func (s *Store) UpdateHuman(_ context.Context, h *model.Human) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.humans[h.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	s.unindexPilot(old.CharacterFields)
+	s.humans[h.ID] = *h
+	s.indexPilot(h.CharacterFields)
+	s.mirrorFriends(h.ID, old.FriendIds, h.FriendIds)
+	return nil
+}
+
+func (s *Store) UpdateDroid(_ context.Context, d *model.Droid) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.droids[d.ID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	s.unindexPilot(old.CharacterFields)
+	s.droids[d.ID] = *d
+	s.indexPilot(d.CharacterFields)
+	s.mirrorFriends(d.ID, old.FriendIds, d.FriendIds)
+	return nil
+}
+
+func (s *Store) DeleteCharacter(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.characterFields(id)
+	if !ok {
+		return store.ErrNotFound
+	}
+	s.unindexPilot(f)
+	delete(s.humans, id)
+	delete(s.droids, id)
+
+	// Slices handed out to readers are shared, so references are dropped
+	// into fresh slices rather than in place.
+	for k, h := range s.humans {
+		if slices.Contains(h.FriendIds, id) {
+			h.FriendIds = without(h.FriendIds, id)
+			s.humans[k] = h
+		}
+	}
+	for k, d := range s.droids {
+		if slices.Contains(d.FriendIds, id) {
+			d.FriendIds = without(d.FriendIds, id)
+			s.droids[k] = d
+		}
+	}
+	for k, f := range s.films {
+		if slices.Contains(f.HeroIds, id) {
+			f.HeroIds = without(f.HeroIds, id)
+			s.films[k] = f
+		}
+	}
+	return nil
+}
+
+func (s *Store) AddFriendship(_ context.Context, a, b string) error {
+	return s.updateFriendship(a, b, withAppended)
+}
+
+func (s *Store) RemoveFriendship(_ context.Context, a, b string) error {
+	return s.updateFriendship(a, b, without)
+}
+
+// updateFriendship replaces the friend lists of a and b with the result of
+// calling update with the list and the ID of the other side.
+func (s *Store) updateFriendship(a, b string, update func(ids []string, id string) []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fa, ok := s.characterFields(a)
+	if !ok {
+		return store.ErrNotFound
+	}
+	fb, ok := s.characterFields(b)
+	if !ok {
+		return store.ErrNotFound
+	}
+	fa.FriendIds = update(fa.FriendIds, b)
+	fb.FriendIds = update(fb.FriendIds, a)
+	s.setCharacterFields(fa)
+	s.setCharacterFields(fb)
+	return nil
+}
+
+// mirrorFriends keeps the friendships of id symmetric once its friend list
+// went from old to friends: its friends list it back, and the characters
+// it dropped drop it too. s.mu must be held.
+func (s *Store) mirrorFriends(id string, old, friends []string) {
+	for _, f := range old {
+		if c, ok := s.characterFields(f); ok && !slices.Contains(friends, f) {
+			c.FriendIds = without(c.FriendIds, id)
+			s.setCharacterFields(c)
+		}
+	}
+	for _, f := range friends {
+		if c, ok := s.characterFields(f); ok && f != id {
+			c.FriendIds = withAppended(c.FriendIds, id)
+			s.setCharacterFields(c)
+		}
+	}
+}
+
+// characterFields returns the shared fields of the human or droid id.
+func (s *Store) characterFields(id string) (model.CharacterFields, bool) {
+	if h, ok := s.humans[id]; ok {
+		return h.CharacterFields, true
+	}
+	if d, ok := s.droids[id]; ok {
+		return d.CharacterFields, true
+	}
+	return model.CharacterFields{}, false
+}
+
+// setCharacterFields stores f on the human or droid it belongs to.
+func (s *Store) setCharacterFields(f model.CharacterFields) {
+	if h, ok := s.humans[f.ID]; ok {
+		h.CharacterFields = f
+		s.humans[f.ID] = h
+	} else if d, ok := s.droids[f.ID]; ok {
+		d.CharacterFields = f
+		s.droids[f.ID] = d
+	}
+}
+
+func (s *Store) CreateStarship(_ context.Context, sh *model.Starship) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := store.NextID(store.StarshipIDBase, keys(s.starships))
+	if err != nil {
+		return err
+	}
+	sh.ID = id
+	s.putStarship(*sh)
+	return nil
+}
+
+func (s *Store) UpdateStarship(_ context.Context, sh *model.Starship) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.starships[sh.ID]; !ok {
+		return store.ErrNotFound
+	}
+	s.putStarship(*sh)
+	return nil
+}
+
+func (s *Store) AppendHistory(_ context.Context, id string, p model.HistoryPoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh, ok := s.starships[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	sh.History = append(slices.Clip(sh.History), p)
+	s.putStarship(sh)
+	return nil
+}
+
+// putStarship stores sh and indexes its last known position.
+func (s *Store) putStarship(sh model.Starship) {
+	s.starships[sh.ID] = sh
+	if p := sh.LastKnownPosition(); p != nil {
+		s.positions.put(sh.ID, p.Coordinate)
+	} else {
+		s.positions.remove(sh.ID)
+	}
+}
+
+func (s *Store) DeleteStarship(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.starships[id]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.starships, id)
+	s.positions.remove(id)
+
+	for _, pilot := range s.pilots[id] {
+		f, _ := s.characterFields(pilot)
+		f.StarshipIds = without(f.StarshipIds, id)
+		s.setCharacterFields(f)
+	}
+	delete(s.pilots, id)
+	return nil
+}
+
+// indexPilot adds f to the pilots of its starships and vehicles.
+func (s *Store) indexPilot(f model.CharacterFields) {
+	for _, id := range f.StarshipIds {
+		s.pilots[id] = withSorted(s.pilots[id], f.ID)
+	}
+	for _, id := range f.VehicleIds {
+		s.vehiclePilots[id] = withSorted(s.vehiclePilots[id], f.ID)
+	}
+}
+
+// unindexPilot drops f from the pilots of its starships and vehicles.
+func (s *Store) unindexPilot(f model.CharacterFields) {
+	for _, id := range f.StarshipIds {
+		s.pilots[id] = without(s.pilots[id], f.ID)
+	}
+	for _, id := range f.VehicleIds {
+		s.vehiclePilots[id] = without(s.vehiclePilots[id], f.ID)
+	}
+}
+
+func (s *Store) Vehicle(_ context.Context, id string) (*model.Vehicle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if v, ok := s.vehicles[id]; ok {
+		return &v, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) Vehicles(_ context.Context) ([]*model.Vehicle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Vehicle, 0, len(s.vehicles))
+	for _, v := range s.vehicles {
+		v := v
+		l = append(l, &v)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l, nil
+}
+
+func (s *Store) VehiclesByIDs(_ context.Context, ids []string) ([]*model.Vehicle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Vehicle, len(ids))
+	for i, id := range ids {
+		if v, ok := s.vehicles[id]; ok {
+			l[i] = &v
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) VehiclePilotIDs(_ context.Context, ids []string) ([][]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return lookupAll(s.vehiclePilots, ids), nil
+}
+
+func (s *Store) Reviews(_ context.Context, episode model.Episode) ([]*model.Review, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Hand out a copy so callers never share a backing array with AddReview.
+	reviews := s.reviews[episode]
+	l := make([]*model.Review, len(reviews))
+	for i, rev := range reviews {
+		rev := *rev
+		l[i] = &rev
+	}
+	return l, nil
+}
+
+func (s *Store) AddReview(_ context.Context, episode model.Episode, review *model.Review) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastReviewID++
+	review.ID = strconv.Itoa(s.lastReviewID)
+	review.Episode = episode
+	rev := *review
+	s.reviews[episode] = append(s.reviews[episode], &rev)
+	s.reviewsByID[rev.ID] = &rev
+	return nil
+}
+
+func (s *Store) ReviewsByIDs(_ context.Context, ids []string) ([]*model.Review, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Review, len(ids))
+	for i, id := range ids {
+		if rev, ok := s.reviewsByID[id]; ok {
+			rev := *rev
+			l[i] = &rev
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) Film(_ context.Context, episode model.Episode) (*model.Film, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if f, ok := s.films[episode]; ok {
+		return &f, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) FilmsByEpisodes(_ context.Context, episodes []model.Episode) ([]*model.Film, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Film, len(episodes))
+	for i, e := range episodes {
+		if f, ok := s.films[e]; ok {
+			l[i] = &f
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) Films(_ context.Context) ([]*model.Film, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Film, 0, len(s.films))
+	for _, f := range s.films {
+		f := f
+		l = append(l, &f)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].EpisodeNumber < l[j].EpisodeNumber })
+	return l, nil
+}
+
+func (s *Store) Planet(_ context.Context, id string) (*model.Planet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if p, ok := s.planets[id]; ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) Planets(_ context.Context) ([]*model.Planet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Planet, 0, len(s.planets))
+	for _, p := range s.planets {
+		p := p
+		l = append(l, &p)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l, nil
+}
+
+func (s *Store) PlanetsByIDs(_ context.Context, ids []string) ([]*model.Planet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Planet, len(ids))
+	for i, id := range ids {
+		if p, ok := s.planets[id]; ok {
+			l[i] = &p
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) ResidentIDs(_ context.Context, ids []string) ([][]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byPlanet := map[string][]string{}
+	for id, h := range s.humans {
+		if h.HomeworldID != "" {
+			byPlanet[h.HomeworldID] = append(byPlanet[h.HomeworldID], id)
+		}
+	}
+	l := make([][]string, len(ids))
+	for i, id := range ids {
+		residents := byPlanet[id]
+		sort.Strings(residents)
+		l[i] = append([]string{}, residents...)
+	}
+	return l, nil
+}
+
+func (s *Store) Species(_ context.Context, id string) (*model.Species, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sp, ok := s.species[id]; ok {
+		return &sp, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) AllSpecies(_ context.Context) ([]*model.Species, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Species, 0, len(s.species))
+	for _, sp := range s.species {
+		sp := sp
+		l = append(l, &sp)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l, nil
+}
+
+func (s *Store) SpeciesByIDs(_ context.Context, ids []string) ([]*model.Species, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := make([]*model.Species, len(ids))
+	for i, id := range ids {
+		if sp, ok := s.species[id]; ok {
+			l[i] = &sp
+		}
+	}
+	return l, nil
+}
+
+func keys[V any](m map[string]V) []string {
+	l := make([]string, 0, len(m))
+	for k := range m {
+		l = append(l, k)
+	}
+	return l
+}
+
+// lookupAll returns a copy of the list m holds for each of ids.
+func lookupAll(m map[string][]string, ids []string) [][]string {
+	l := make([][]string, len(ids))
+	for i, id := range ids {
+		l[i] = append([]string{}, m[id]...)
+	}
+	return l
+}
+
+// withSorted returns a new slice holding the sorted ids plus id, in order.
+// ids is returned as is when it already holds id.
+func withSorted(ids []string, id string) []string {
+	i, found := slices.BinarySearch(ids, id)
+	if found {
+		return ids
+	}
+	return slices.Insert(slices.Clone(ids), i, id)
+}
+
+// withAppended returns ids with id appended, or ids itself when it already
+// holds id. ids is left untouched.
+func withAppended(ids []string, id string) []string {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(slices.Clip(ids), id)
+}
+
+// without returns a new slice holding ids minus every occurrence of id.
+func without(ids []string, id string) []string {
+	l := make([]string, 0, len(ids))
+	for _, v := range ids {
+		if v != id {
+			l = append(l, v)
+		}
+	}
+	return l
+}
@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order, each one exactly once. The number of
+// migrations already applied is tracked in SQLite's user_version pragma,
+// so new schema changes must be appended and never edited in place.
+var migrations = []string{
+	`
+CREATE TABLE characters (
+	id               TEXT PRIMARY KEY,
+	kind             TEXT NOT NULL CHECK (kind IN ('HUMAN', 'DROID')),
+	name             TEXT NOT NULL,
+	height_meters    REAL,
+	mass             REAL,
+	primary_function TEXT
+);
+
+CREATE TABLE character_friends (
+	character_id TEXT NOT NULL REFERENCES characters (id) ON DELETE CASCADE,
+	position     INTEGER NOT NULL,
+	friend_id    TEXT NOT NULL,
+	PRIMARY KEY (character_id, position)
+);
+
+CREATE TABLE character_episodes (
+	character_id TEXT NOT NULL REFERENCES characters (id) ON DELETE CASCADE,
+	position     INTEGER NOT NULL,
+	episode      TEXT NOT NULL,
+	PRIMARY KEY (character_id, position)
+);
+
+CREATE TABLE starships (
+	id     TEXT PRIMARY KEY,
+	name   TEXT NOT NULL,
+	length REAL NOT NULL
+);
+
+CREATE TABLE human_starships (
+	human_id    TEXT NOT NULL REFERENCES characters (id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	starship_id TEXT NOT NULL,
+	PRIMARY KEY (human_id, position)
+);
+
+CREATE TABLE starship_history (
+	starship_id TEXT NOT NULL REFERENCES starships (id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	x           INTEGER NOT NULL,
+	y           INTEGER NOT NULL,
+	PRIMARY KEY (starship_id, position)
+);
+
+CREATE TABLE reviews (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	episode    TEXT NOT NULL,
+	stars      INTEGER NOT NULL,
+	commentary TEXT,
+	time       TEXT NOT NULL
+);
+
+CREATE INDEX reviews_episode ON reviews (episode, id);
+`,
+	`
+CREATE TABLE films (
+	episode TEXT PRIMARY KEY,
+	title   TEXT NOT NULL
+);
+`,
+	`
+CREATE TABLE film_heroes (
+	episode      TEXT NOT NULL REFERENCES films (episode) ON DELETE CASCADE,
+	position     INTEGER NOT NULL,
+	character_id TEXT NOT NULL REFERENCES characters (id) ON DELETE CASCADE,
+	PRIMARY KEY (episode, position)
+);
+`,
+	`
+ALTER TABLE films ADD COLUMN episode_number INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE films ADD COLUMN release_date TEXT NOT NULL DEFAULT '';
+ALTER TABLE films ADD COLUMN director TEXT NOT NULL DEFAULT '';
+ALTER TABLE films ADD COLUMN opening_crawl TEXT NOT NULL DEFAULT '';
+`,
+	`
+CREATE TABLE planets (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	climate    TEXT NOT NULL,
+	terrain    TEXT NOT NULL,
+	population REAL
+);
+
+CREATE TABLE species (
+	id             TEXT PRIMARY KEY,
+	name           TEXT NOT NULL,
+	classification TEXT NOT NULL,
+	language       TEXT NOT NULL
+);
+
+ALTER TABLE characters ADD COLUMN homeworld_id TEXT REFERENCES planets (id);
+ALTER TABLE characters ADD COLUMN species_id TEXT REFERENCES species (id);
+
+CREATE INDEX characters_homeworld ON characters (homeworld_id, id);
+`,
+	`
+ALTER TABLE human_starships RENAME TO character_starships;
+ALTER TABLE character_starships RENAME COLUMN human_id TO character_id;
+
+CREATE INDEX character_starships_starship ON character_starships (starship_id, character_id);
+
+CREATE TABLE vehicles (
+	id            TEXT PRIMARY KEY,
+	name          TEXT NOT NULL,
+	model         TEXT NOT NULL,
+	vehicle_class TEXT NOT NULL,
+	length        REAL NOT NULL
+);
+
+CREATE TABLE character_vehicles (
+	character_id TEXT NOT NULL REFERENCES characters (id) ON DELETE CASCADE,
+	position     INTEGER NOT NULL,
+	vehicle_id   TEXT NOT NULL REFERENCES vehicles (id),
+	PRIMARY KEY (character_id, position)
+);
+
+CREATE INDEX character_vehicles_vehicle ON character_vehicles (vehicle_id, character_id);
+`,
+	`
+ALTER TABLE starship_history ADD COLUMN z INTEGER;
+ALTER TABLE starship_history ADD COLUMN timestamp TEXT;
+ALTER TABLE starship_history ADD COLUMN location_name TEXT;
+`,
+	`
+-- The last known position of every starship. R*Tree keys are integers,
+-- which starship IDs are.
+CREATE VIRTUAL TABLE starship_positions USING rtree_i32 (id, min_x, max_x, min_y, max_y);
+
+INSERT INTO starship_positions (id, min_x, max_x, min_y, max_y)
+SELECT CAST(h.starship_id AS INTEGER), h.x, h.x, h.y, h.y
+FROM starship_history h
+WHERE h.position = (SELECT MAX(position) FROM starship_history WHERE starship_id = h.starship_id);
+`,
+}
+
+// migrate brings the schema of db up to date.
+func migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
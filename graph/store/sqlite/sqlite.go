@@ -0,0 +1,1200 @@
+// Package sqlite is the SQLite implementation of the store repositories,
+// built on a pure-Go driver so the server stays free of cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store persists every entity in a single SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it if needed, and applies any
+// pending migrations. Use ":memory:" for a throwaway database.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite serialises writers anyway, and a single connection keeps
+	// ":memory:" databases from being split across connections.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close releases the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Repository exposes the store through the store interfaces.
+func (s *Store) Repository() store.Repository {
+	return store.Repository{
+		Characters: s,
+		Starships:  s,
+		Vehicles:   s,
+		Reviews:    s,
+		Films:      s,
+		Planets:    s,
+		Species:    s,
+	}
+}
+
+// Seed loads data into the database unless it already holds characters,
+// so restarting the server keeps whatever was written since. Films,
+// planets, species and vehicles are seeded apart, so databases created
+// before they existed get them too.
+func (s *Store) Seed(ctx context.Context, data store.Dataset) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Planets and species come first for the humans to refer to them.
+	newPlanets, err := isEmpty(ctx, tx, "planets")
+	if err != nil {
+		return err
+	}
+	if newPlanets {
+		for i := range data.Planets {
+			if err := insertPlanet(ctx, tx, &data.Planets[i]); err != nil {
+				return err
+			}
+		}
+	}
+	newSpecies, err := isEmpty(ctx, tx, "species")
+	if err != nil {
+		return err
+	}
+	if newSpecies {
+		for i := range data.Species {
+			if err := insertSpecies(ctx, tx, &data.Species[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	newVehicles, err := isEmpty(ctx, tx, "vehicles")
+	if err != nil {
+		return err
+	}
+	if newVehicles {
+		for i := range data.Vehicles {
+			if err := insertVehicle(ctx, tx, &data.Vehicles[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	empty, err := isEmpty(ctx, tx, "characters")
+	if err != nil {
+		return err
+	}
+	if empty {
+		for i := range data.Humans {
+			if err := insertHuman(ctx, tx, &data.Humans[i]); err != nil {
+				return err
+			}
+		}
+		for i := range data.Droids {
+			if err := insertDroid(ctx, tx, &data.Droids[i]); err != nil {
+				return err
+			}
+		}
+		for i := range data.Starships {
+			if err := insertStarship(ctx, tx, &data.Starships[i]); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Describe the tracks stored before their points had a time and
+		// place.
+		for i := range data.Starships {
+			if err := describeHistory(ctx, tx, &data.Starships[i]); err != nil {
+				return err
+			}
+		}
+		// List the episodes beyond the original trilogy for the characters
+		// stored before the Episode enum held them.
+		for i := range data.Humans {
+			if err := addEpisodes(ctx, tx, &data.Humans[i].CharacterFields); err != nil {
+				return err
+			}
+		}
+		for i := range data.Droids {
+			if err := addEpisodes(ctx, tx, &data.Droids[i].CharacterFields); err != nil {
+				return err
+			}
+		}
+		// Link the characters stored before planets, species and vehicles
+		// existed.
+		for i := range data.Humans {
+			if err := linkHuman(ctx, tx, &data.Humans[i], newPlanets, newSpecies); err != nil {
+				return err
+			}
+		}
+		if newVehicles {
+			for i := range data.Humans {
+				if err := insertCharacterVehicles(ctx, tx, &data.Humans[i].CharacterFields); err != nil {
+					return err
+				}
+			}
+			for i := range data.Droids {
+				if err := insertCharacterVehicles(ctx, tx, &data.Droids[i].CharacterFields); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for i := range data.Films {
+		if err := seedFilm(ctx, tx, &data.Films[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) Human(ctx context.Context, id string) (*model.Human, error) {
+	l, err := s.humans(ctx, "AND id = ?", id)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) Droid(ctx context.Context, id string) (*model.Droid, error) {
+	l, err := s.droids(ctx, "AND id = ?", id)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) Humans(ctx context.Context) ([]*model.Human, error) {
+	return s.humans(ctx, "")
+}
+
+func (s *Store) Droids(ctx context.Context) ([]*model.Droid, error) {
+	return s.droids(ctx, "")
+}
+
+func (s *Store) CharactersByIDs(ctx context.Context, ids []string) ([]model.Character, error) {
+	where, args := in("id", ids)
+	humans, err := s.humans(ctx, "AND "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	droids, err := s.droids(ctx, "AND "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]model.Character, len(humans)+len(droids))
+	for _, h := range humans {
+		byID[h.ID] = h
+	}
+	for _, d := range droids {
+		byID[d.ID] = d
+	}
+	l := make([]model.Character, len(ids))
+	for i, id := range ids {
+		l[i] = byID[id]
+	}
+	return l, nil
+}
+
+func (s *Store) Starship(ctx context.Context, id string) (*model.Starship, error) {
+	l, err := s.starships(ctx, "WHERE id = ?", id)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) Starships(ctx context.Context) ([]*model.Starship, error) {
+	return s.starships(ctx, "")
+}
+
+func (s *Store) StarshipsByIDs(ctx context.Context, ids []string) ([]*model.Starship, error) {
+	where, args := in("id", ids)
+	starships, err := s.starships(ctx, "WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Starship, len(starships))
+	for _, sh := range starships {
+		byID[sh.ID] = sh
+	}
+	l := make([]*model.Starship, len(ids))
+	for i, id := range ids {
+		l[i] = byID[id]
+	}
+	return l, nil
+}
+
+func (s *Store) StarshipsNear(ctx context.Context, x, y int, radius float64) ([]*model.Starship, error) {
+	positions, err := s.positions(ctx, model.BoxAround(x, y, radius))
+	if err != nil {
+		return nil, err
+	}
+
+	dist := make(map[string]float64, len(positions))
+	var ids []string
+	for id, p := range positions {
+		if d := math.Hypot(float64(p.X)-float64(x), float64(p.Y)-float64(y)); d <= radius {
+			dist[id] = d
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if dist[ids[i]] != dist[ids[j]] {
+			return dist[ids[i]] < dist[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return s.starshipsOf(ctx, ids)
+}
+
+func (s *Store) StarshipsInRegion(ctx context.Context, box model.BoundingBox) ([]*model.Starship, error) {
+	positions, err := s.positions(ctx, box)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(positions))
+	for id := range positions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return s.starshipsOf(ctx, ids)
+}
+
+// positions returns the last known positions lying in box, by starship ID.
+func (s *Store) positions(ctx context.Context, box model.BoundingBox) (map[string]model.Coordinate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, min_x, min_y FROM starship_positions
+WHERE min_x >= ? AND max_x <= ? AND min_y >= ? AND max_y <= ?`,
+		box.MinX, box.MaxX, box.MinY, box.MaxY)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	m := map[string]model.Coordinate{}
+	for rows.Next() {
+		var (
+			id int64
+			p  model.Coordinate
+		)
+		if err := rows.Scan(&id, &p.X, &p.Y); err != nil {
+			return nil, err
+		}
+		m[strconv.FormatInt(id, 10)] = p
+	}
+	return m, rows.Err()
+}
+
+// starshipsOf looks up the starships of ids, keeping their order and
+// leaving out the unknown ones.
+func (s *Store) starshipsOf(ctx context.Context, ids []string) ([]*model.Starship, error) {
+	starships, err := s.StarshipsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	l := make([]*model.Starship, 0, len(starships))
+	for _, sh := range starships {
+		if sh != nil {
+			l = append(l, sh)
+		}
+	}
+	return l, nil
+}
+
+func (s *Store) StarshipPilotIDs(ctx context.Context, ids []string) ([][]string, error) {
+	return s.pilotIDs(ctx, "character_starships", "starship_id", ids)
+}
+
+func (s *Store) Vehicle(ctx context.Context, id string) (*model.Vehicle, error) {
+	l, err := s.vehicles(ctx, "WHERE id = ?", id)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) Vehicles(ctx context.Context) ([]*model.Vehicle, error) {
+	return s.vehicles(ctx, "")
+}
+
+func (s *Store) VehiclesByIDs(ctx context.Context, ids []string) ([]*model.Vehicle, error) {
+	where, args := in("id", ids)
+	vehicles, err := s.vehicles(ctx, "WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Vehicle, len(vehicles))
+	for _, v := range vehicles {
+		byID[v.ID] = v
+	}
+	l := make([]*model.Vehicle, len(ids))
+	for i, id := range ids {
+		l[i] = byID[id]
+	}
+	return l, nil
+}
+
+func (s *Store) VehiclePilotIDs(ctx context.Context, ids []string) ([][]string, error) {
+	return s.pilotIDs(ctx, "character_vehicles", "vehicle_id", ids)
+}
+
+// pilotIDs returns, for each of ids, the characters that table links to it
+// through column, ordered by ID.
+func (s *Store) pilotIDs(ctx context.Context, table, column string, ids []string) ([][]string, error) {
+	where, args := in(column, ids)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT DISTINCT "+column+", character_id FROM "+table+" WHERE "+where+" ORDER BY character_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := map[string][]string{}
+	for rows.Next() {
+		var id, characterID string
+		if err := rows.Scan(&id, &characterID); err != nil {
+			return nil, err
+		}
+		byID[id] = append(byID[id], characterID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	l := make([][]string, len(ids))
+	for i, id := range ids {
+		l[i] = append([]string{}, byID[id]...)
+	}
+	return l, nil
+}
+
+// vehicles loads the vehicles matching the where clause, ordered by ID.
+func (s *Store) vehicles(ctx context.Context, where string, args ...interface{}) ([]*model.Vehicle, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, model, vehicle_class, length FROM vehicles "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var l []*model.Vehicle
+	for rows.Next() {
+		var v model.Vehicle
+		if err := rows.Scan(&v.ID, &v.Name, &v.Model, &v.VehicleClass, &v.Length); err != nil {
+			return nil, err
+		}
+		l = append(l, &v)
+	}
+	return l, rows.Err()
+}
+
+func (s *Store) Reviews(ctx context.Context, episode model.Episode) ([]*model.Review, error) {
+	return s.reviews(ctx, "WHERE episode = ?", episode)
+}
+
+func (s *Store) ReviewsByIDs(ctx context.Context, ids []string) ([]*model.Review, error) {
+	where, args := in("id", ids)
+	reviews, err := s.reviews(ctx, "WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Review, len(reviews))
+	for _, rev := range reviews {
+		byID[rev.ID] = rev
+	}
+	l := make([]*model.Review, len(ids))
+	for i, id := range ids {
+		l[i] = byID[id]
+	}
+	return l, nil
+}
+
+func (s *Store) AddReview(ctx context.Context, episode model.Episode, review *model.Review) error {
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO reviews (episode, stars, commentary, time) VALUES (?, ?, ?, ?)",
+		episode, review.Stars, review.Commentary, review.Time.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	review.ID = strconv.FormatInt(id, 10)
+	review.Episode = episode
+	return nil
+}
+
+func (s *Store) Planet(ctx context.Context, id string) (*model.Planet, error) {
+	l, err := s.planets(ctx, "WHERE id = ?", id)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) Planets(ctx context.Context) ([]*model.Planet, error) {
+	return s.planets(ctx, "")
+}
+
+func (s *Store) PlanetsByIDs(ctx context.Context, ids []string) ([]*model.Planet, error) {
+	where, args := in("id", ids)
+	planets, err := s.planets(ctx, "WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Planet, len(planets))
+	for _, p := range planets {
+		byID[p.ID] = p
+	}
+	l := make([]*model.Planet, len(ids))
+	for i, id := range ids {
+		l[i] = byID[id]
+	}
+	return l, nil
+}
+
+func (s *Store) ResidentIDs(ctx context.Context, ids []string) ([][]string, error) {
+	where, args := in("homeworld_id", ids)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT homeworld_id, id FROM characters WHERE kind = 'HUMAN' AND "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byPlanet := map[string][]string{}
+	for rows.Next() {
+		var planetID, id string
+		if err := rows.Scan(&planetID, &id); err != nil {
+			return nil, err
+		}
+		byPlanet[planetID] = append(byPlanet[planetID], id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	l := make([][]string, len(ids))
+	for i, id := range ids {
+		l[i] = append([]string{}, byPlanet[id]...)
+	}
+	return l, nil
+}
+
+func (s *Store) Species(ctx context.Context, id string) (*model.Species, error) {
+	l, err := s.species(ctx, "WHERE id = ?", id)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) AllSpecies(ctx context.Context) ([]*model.Species, error) {
+	return s.species(ctx, "")
+}
+
+func (s *Store) SpeciesByIDs(ctx context.Context, ids []string) ([]*model.Species, error) {
+	where, args := in("id", ids)
+	species, err := s.species(ctx, "WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Species, len(species))
+	for _, sp := range species {
+		byID[sp.ID] = sp
+	}
+	l := make([]*model.Species, len(ids))
+	for i, id := range ids {
+		l[i] = byID[id]
+	}
+	return l, nil
+}
+
+// planets loads the planets matching the where clause, ordered by ID.
+func (s *Store) planets(ctx context.Context, where string, args ...interface{}) ([]*model.Planet, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, climate, terrain, population FROM planets "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var l []*model.Planet
+	for rows.Next() {
+		var (
+			p          model.Planet
+			population sql.NullFloat64
+		)
+		if err := rows.Scan(&p.ID, &p.Name, &p.Climate, &p.Terrain, &population); err != nil {
+			return nil, err
+		}
+		if population.Valid {
+			p.Population = &population.Float64
+		}
+		l = append(l, &p)
+	}
+	return l, rows.Err()
+}
+
+// species loads the species matching the where clause, ordered by ID.
+func (s *Store) species(ctx context.Context, where string, args ...interface{}) ([]*model.Species, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, classification, language FROM species "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var l []*model.Species
+	for rows.Next() {
+		var sp model.Species
+		if err := rows.Scan(&sp.ID, &sp.Name, &sp.Classification, &sp.Language); err != nil {
+			return nil, err
+		}
+		l = append(l, &sp)
+	}
+	return l, rows.Err()
+}
+
+func (s *Store) Film(ctx context.Context, episode model.Episode) (*model.Film, error) {
+	l, err := s.films(ctx, "WHERE episode = ?", episode)
+	if err != nil || len(l) == 0 {
+		return nil, err
+	}
+	return l[0], nil
+}
+
+func (s *Store) Films(ctx context.Context) ([]*model.Film, error) {
+	return s.films(ctx, "")
+}
+
+func (s *Store) FilmsByEpisodes(ctx context.Context, episodes []model.Episode) ([]*model.Film, error) {
+	values := make([]string, len(episodes))
+	for i, e := range episodes {
+		values[i] = e.String()
+	}
+	where, args := in("episode", values)
+	films, err := s.films(ctx, "WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byEpisode := make(map[model.Episode]*model.Film, len(films))
+	for _, f := range films {
+		byEpisode[f.Episode] = f
+	}
+	l := make([]*model.Film, len(episodes))
+	for i, e := range episodes {
+		l[i] = byEpisode[e]
+	}
+	return l, nil
+}
+
+// films loads the films matching the where clause, ordered by episode
+// number.
+func (s *Store) films(ctx context.Context, where string, args ...interface{}) ([]*model.Film, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT episode, title, episode_number, release_date, director, opening_crawl FROM films "+where+
+			" ORDER BY episode_number", args...)
+	if err != nil {
+		return nil, err
+	}
+	var l []*model.Film
+	for rows.Next() {
+		var (
+			f           model.Film
+			releaseDate string
+		)
+		if err := rows.Scan(&f.Episode, &f.Title, &f.EpisodeNumber, &releaseDate, &f.Director, &f.OpeningCrawl); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if f.ReleaseDate, err = time.Parse(model.DateLayout, releaseDate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		l = append(l, &f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(l) == 0 {
+		return l, nil
+	}
+	episodes := make([]string, len(l))
+	for i, f := range l {
+		episodes[i] = string(f.Episode)
+	}
+	heroes, err := s.links(ctx, "film_heroes", "episode", "character_id", episodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range l {
+		f.HeroIds = heroes[string(f.Episode)]
+	}
+	return l, nil
+}
+
+// reviews loads the reviews matching the where clause, in the order they
+// were added.
+func (s *Store) reviews(ctx context.Context, where string, args ...interface{}) ([]*model.Review, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, episode, stars, commentary, time FROM reviews "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var l []*model.Review
+	for rows.Next() {
+		var (
+			rev        model.Review
+			commentary sql.NullString
+			t          string
+		)
+		if err := rows.Scan(&rev.ID, &rev.Episode, &rev.Stars, &commentary, &t); err != nil {
+			return nil, err
+		}
+		if commentary.Valid {
+			rev.Commentary = &commentary.String
+		}
+		if rev.Time, err = time.Parse(time.RFC3339Nano, t); err != nil {
+			return nil, err
+		}
+		l = append(l, &rev)
+	}
+	return l, rows.Err()
+}
+
+// humans loads the humans matching the extra where clause, ordered by ID.
+func (s *Store) humans(ctx context.Context, where string, args ...interface{}) ([]*model.Human, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, height_meters, mass, homeworld_id, species_id FROM characters WHERE kind = 'HUMAN' "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	var l []*model.Human
+	for rows.Next() {
+		var (
+			h                      model.Human
+			homeworldID, speciesID sql.NullString
+		)
+		if err := rows.Scan(&h.ID, &h.Name, &h.HeightMeters, &h.Mass, &homeworldID, &speciesID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		h.HomeworldID, h.SpeciesID = homeworldID.String, speciesID.String
+		l = append(l, &h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fields := make([]*model.CharacterFields, len(l))
+	for i, h := range l {
+		fields[i] = &h.CharacterFields
+	}
+	if err := s.loadCharacterFields(ctx, fields); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// droids loads the droids matching the extra where clause, ordered by ID.
+func (s *Store) droids(ctx context.Context, where string, args ...interface{}) ([]*model.Droid, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, primary_function FROM characters WHERE kind = 'DROID' "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	var l []*model.Droid
+	for rows.Next() {
+		var d model.Droid
+		if err := rows.Scan(&d.ID, &d.Name, &d.PrimaryFunction); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		l = append(l, &d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fields := make([]*model.CharacterFields, len(l))
+	for i, d := range l {
+		fields[i] = &d.CharacterFields
+	}
+	if err := s.loadCharacterFields(ctx, fields); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// starships loads the starships matching the where clause, ordered by ID.
+func (s *Store) starships(ctx context.Context, where string, args ...interface{}) ([]*model.Starship, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, length FROM starships "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	var l []*model.Starship
+	for rows.Next() {
+		var sh model.Starship
+		if err := rows.Scan(&sh.ID, &sh.Name, &sh.Length); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		l = append(l, &sh)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(l) == 0 {
+		return l, nil
+	}
+	ids := make([]string, len(l))
+	for i, sh := range l {
+		ids[i] = sh.ID
+	}
+	histories, err := s.histories(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, sh := range l {
+		sh.History = append([]model.HistoryPoint{}, histories[sh.ID]...)
+	}
+	return l, nil
+}
+
+// loadCharacterFields fills the lists of cs, with one query per list
+// whatever the number of characters.
+func (s *Store) loadCharacterFields(ctx context.Context, cs []*model.CharacterFields) error {
+	if len(cs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(cs))
+	for i, c := range cs {
+		ids[i] = c.ID
+	}
+	friends, err := s.links(ctx, "character_friends", "character_id", "friend_id", ids)
+	if err != nil {
+		return err
+	}
+	episodes, err := s.links(ctx, "character_episodes", "character_id", "episode", ids)
+	if err != nil {
+		return err
+	}
+	starships, err := s.links(ctx, "character_starships", "character_id", "starship_id", ids)
+	if err != nil {
+		return err
+	}
+	vehicles, err := s.links(ctx, "character_vehicles", "character_id", "vehicle_id", ids)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range cs {
+		c.FriendIds = friends[c.ID]
+		c.AppearsIn = make([]model.Episode, len(episodes[c.ID]))
+		for i, e := range episodes[c.ID] {
+			c.AppearsIn[i] = model.Episode(e)
+		}
+		c.StarshipIds = starships[c.ID]
+		c.VehicleIds = vehicles[c.ID]
+	}
+	return nil
+}
+
+// links returns column of the rows of table linked through key to each of
+// keys, in the order of their position.
+func (s *Store) links(ctx context.Context, table, key, column string, keys []string) (map[string][]string, error) {
+	where, args := in(key, keys)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+key+", "+column+" FROM "+table+" WHERE "+where+" ORDER BY "+key+", position", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := map[string][]string{}
+	for rows.Next() {
+		var id, v string
+		if err := rows.Scan(&id, &v); err != nil {
+			return nil, err
+		}
+		byID[id] = append(byID[id], v)
+	}
+	return byID, rows.Err()
+}
+
+// histories loads the tracks of the starships of ids in one query.
+func (s *Store) histories(ctx context.Context, ids []string) (map[string][]model.HistoryPoint, error) {
+	where, args := in("starship_id", ids)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT starship_id, x, y, z, timestamp, location_name FROM starship_history WHERE "+where+" ORDER BY starship_id, position", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := map[string][]model.HistoryPoint{}
+	for rows.Next() {
+		var (
+			id                      string
+			p                       model.HistoryPoint
+			z                       sql.NullInt64
+			timestamp, locationName sql.NullString
+		)
+		if err := rows.Scan(&id, &p.Coordinate.X, &p.Coordinate.Y, &z, &timestamp, &locationName); err != nil {
+			return nil, err
+		}
+		if z.Valid {
+			v := int(z.Int64)
+			p.Coordinate.Z = &v
+		}
+		if timestamp.Valid {
+			t, err := time.Parse(time.RFC3339Nano, timestamp.String)
+			if err != nil {
+				return nil, err
+			}
+			p.Timestamp = &t
+		}
+		if locationName.Valid {
+			p.LocationName = &locationName.String
+		}
+		byID[id] = append(byID[id], p)
+	}
+	return byID, rows.Err()
+}
+
+// in builds a "column IN (...)" condition matching values.
+func in(column string, values []string) (string, []interface{}) {
+	if len(values) == 0 {
+		return "0", nil
+	}
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return column + " IN (?" + strings.Repeat(", ?", len(values)-1) + ")", args
+}
+
+// isEmpty reports whether table holds no row.
+func isEmpty(ctx context.Context, tx *sql.Tx, table string) (bool, error) {
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil {
+		return false, err
+	}
+	return n == 0, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// selectStrings runs a query selecting a single text column.
+func selectStrings(ctx context.Context, db queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var l []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		l = append(l, v)
+	}
+	return l, rows.Err()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertHuman(ctx context.Context, db execer, h *model.Human) error {
+	if _, err := db.ExecContext(ctx,
+		"INSERT INTO characters (id, kind, name, height_meters, mass, homeworld_id, species_id) VALUES (?, 'HUMAN', ?, ?, ?, ?, ?)",
+		h.ID, h.Name, h.HeightMeters, h.Mass, nullIfEmpty(h.HomeworldID), nullIfEmpty(h.SpeciesID)); err != nil {
+		return err
+	}
+	return insertCharacterFields(ctx, db, &h.CharacterFields)
+}
+
+func insertDroid(ctx context.Context, db execer, d *model.Droid) error {
+	if _, err := db.ExecContext(ctx,
+		"INSERT INTO characters (id, kind, name, primary_function) VALUES (?, 'DROID', ?, ?)",
+		d.ID, d.Name, d.PrimaryFunction); err != nil {
+		return err
+	}
+	return insertCharacterFields(ctx, db, &d.CharacterFields)
+}
+
+func insertCharacterFields(ctx context.Context, db execer, c *model.CharacterFields) error {
+	for i, id := range c.FriendIds {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO character_friends (character_id, position, friend_id) VALUES (?, ?, ?)",
+			c.ID, i, id); err != nil {
+			return err
+		}
+	}
+	for i, e := range c.AppearsIn {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO character_episodes (character_id, position, episode) VALUES (?, ?, ?)",
+			c.ID, i, e); err != nil {
+			return err
+		}
+	}
+	for i, id := range c.StarshipIds {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO character_starships (character_id, position, starship_id) VALUES (?, ?, ?)",
+			c.ID, i, id); err != nil {
+			return err
+		}
+	}
+	return insertCharacterVehicles(ctx, db, c)
+}
+
+// insertCharacterVehicles links the character of c to its vehicles, unless
+// it is not stored.
+func insertCharacterVehicles(ctx context.Context, db execer, c *model.CharacterFields) error {
+	for i, id := range c.VehicleIds {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO character_vehicles (character_id, position, vehicle_id) SELECT id, ?, ? FROM characters WHERE id = ?",
+			i, id, c.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertVehicle(ctx context.Context, db execer, v *model.Vehicle) error {
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO vehicles (id, name, model, vehicle_class, length) VALUES (?, ?, ?, ?, ?)",
+		v.ID, v.Name, v.Model, v.VehicleClass, v.Length)
+	return err
+}
+
+func insertStarship(ctx context.Context, db execer, sh *model.Starship) error {
+	if _, err := db.ExecContext(ctx,
+		"INSERT INTO starships (id, name, length) VALUES (?, ?, ?)",
+		sh.ID, sh.Name, sh.Length); err != nil {
+		return err
+	}
+	return insertHistory(ctx, db, sh)
+}
+
+func insertHistory(ctx context.Context, db execer, sh *model.Starship) error {
+	for i, p := range sh.History {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO starship_history (starship_id, position, x, y, z, timestamp, location_name) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			sh.ID, i, p.Coordinate.X, p.Coordinate.Y, p.Coordinate.Z, formatTimestamp(p.Timestamp), p.LocationName); err != nil {
+			return err
+		}
+	}
+	return indexPosition(ctx, db, sh.ID)
+}
+
+// indexPosition sets the position of the starship id in starship_positions
+// to the last point of its stored track, dropping it when there is none.
+func indexPosition(ctx context.Context, db execer, id string) error {
+	if _, err := db.ExecContext(ctx,
+		"DELETE FROM starship_positions WHERE id = CAST(? AS INTEGER)", id); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, `
+INSERT INTO starship_positions (id, min_x, max_x, min_y, max_y)
+SELECT CAST(starship_id AS INTEGER), x, x, y, y FROM starship_history
+WHERE starship_id = ? ORDER BY position DESC LIMIT 1`, id)
+	return err
+}
+
+// describeHistory fills in the height, time and place of the stored points
+// of sh that still sit where sh has them and were never described.
+func describeHistory(ctx context.Context, db execer, sh *model.Starship) error {
+	for i, p := range sh.History {
+		if _, err := db.ExecContext(ctx, `
+UPDATE starship_history SET z = ?, timestamp = ?, location_name = ?
+WHERE starship_id = ? AND position = ? AND x = ? AND y = ?
+	AND z IS NULL AND timestamp IS NULL AND location_name IS NULL`,
+			p.Coordinate.Z, formatTimestamp(p.Timestamp), p.LocationName,
+			sh.ID, i, p.Coordinate.X, p.Coordinate.Y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatTimestamp stores t the way review times are, or as NULL when nil.
+func formatTimestamp(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(time.RFC3339Nano), Valid: true}
+}
+
+// seedFilm inserts f unless its episode is already stored with its
+// details, which are filled in when missing. The heroes of f are inserted
+// along, unless the stored film already has some.
+func seedFilm(ctx context.Context, tx *sql.Tx, f *model.Film) error {
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO films (episode, title, episode_number, release_date, director, opening_crawl)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (episode) DO UPDATE SET
+	episode_number = excluded.episode_number,
+	release_date = excluded.release_date,
+	director = excluded.director,
+	opening_crawl = excluded.opening_crawl
+WHERE films.release_date = ''`,
+		f.Episode, f.Title, f.EpisodeNumber, f.ReleaseDate.Format(model.DateLayout), f.Director, f.OpeningCrawl)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	var heroes int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM film_heroes WHERE episode = ?", f.Episode).Scan(&heroes); err != nil {
+		return err
+	}
+	if heroes > 0 {
+		return nil
+	}
+	return insertFilmHeroes(ctx, tx, f)
+}
+
+// insertFilmHeroes stores the heroes of f, skipping the characters that do
+// not exist or no longer do.
+func insertFilmHeroes(ctx context.Context, db execer, f *model.Film) error {
+	for i, id := range f.HeroIds {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO film_heroes (episode, position, character_id) SELECT ?, ?, id FROM characters WHERE id = ?",
+			f.Episode, i, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertPlanet(ctx context.Context, db execer, p *model.Planet) error {
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO planets (id, name, climate, terrain, population) VALUES (?, ?, ?, ?, ?)",
+		p.ID, p.Name, p.Climate, p.Terrain, p.Population)
+	return err
+}
+
+func insertSpecies(ctx context.Context, db execer, sp *model.Species) error {
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO species (id, name, classification, language) VALUES (?, ?, ?, ?)",
+		sp.ID, sp.Name, sp.Classification, sp.Language)
+	return err
+}
+
+// linkHuman sets the homeworld and species of the stored human with the
+// ID of h to those of h, for the newly seeded planets or species only and
+// unless they were set since.
+// trilogy holds the episodes the Episode enum started with.
+var trilogy = []model.Episode{model.EpisodeNewhope, model.EpisodeEmpire, model.EpisodeJedi}
+
+// addEpisodes appends the episodes of c beyond the original trilogy to the
+// stored character of c, unless it already lists one of them and was thus
+// stored after the Episode enum was extended.
+func addEpisodes(ctx context.Context, tx *sql.Tx, c *model.CharacterFields) error {
+	var newer []model.Episode
+	for _, e := range c.AppearsIn {
+		if !slices.Contains(trilogy, e) {
+			newer = append(newer, e)
+		}
+	}
+	if len(newer) == 0 {
+		return nil
+	}
+
+	var listed int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM character_episodes WHERE character_id = ? AND episode NOT IN (?, ?, ?)",
+		c.ID, trilogy[0], trilogy[1], trilogy[2]).Scan(&listed); err != nil {
+		return err
+	}
+	if listed > 0 {
+		return nil
+	}
+	for _, e := range newer {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO character_episodes (character_id, position, episode)
+SELECT id, (SELECT COALESCE(MAX(position) + 1, 0) FROM character_episodes WHERE character_id = ?), ?
+FROM characters WHERE id = ?`,
+			c.ID, e, c.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func linkHuman(ctx context.Context, db execer, h *model.Human, planets, species bool) error {
+	if planets && h.HomeworldID != "" {
+		if _, err := db.ExecContext(ctx,
+			"UPDATE characters SET homeworld_id = ? WHERE id = ? AND kind = 'HUMAN' AND homeworld_id IS NULL",
+			h.HomeworldID, h.ID); err != nil {
+			return err
+		}
+	}
+	if species && h.SpeciesID != "" {
+		if _, err := db.ExecContext(ctx,
+			"UPDATE characters SET species_id = ? WHERE id = ? AND kind = 'HUMAN' AND species_id IS NULL",
+			h.SpeciesID, h.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullIfEmpty stores an empty reference as NULL, which foreign keys allow.
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,92 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MatsuoTakuro/starwars/graph/store"
+	"github.com/MatsuoTakuro/starwars/graph/store/memory"
+	"github.com/MatsuoTakuro/starwars/graph/store/sqlite"
+	"github.com/MatsuoTakuro/starwars/graph/store/storetest"
+)
+
+func openFixtures(t *testing.T) *sqlite.Store {
+	return storetest.OpenSQLite(t, filepath.Join(t.TempDir(), "starwars.db"))
+}
+
+// openOutdated returns a store seeded with the fixtures the way servers
+// did before the Episode enum went beyond the original trilogy, then
+// reopened and seeded again as a restart does.
+func openOutdated(t *testing.T) *sqlite.Store {
+	path := filepath.Join(t.TempDir(), "starwars.db")
+	storetest.OpenSQLite(t, path)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(
+		"DELETE FROM character_episodes WHERE episode NOT IN ('NEWHOPE', 'EMPIRE', 'JEDI')"); err != nil {
+		t.Fatal(err)
+	}
+	return storetest.OpenSQLite(t, path)
+}
+
+// TestMatchesMemory checks that the batched loading of the lists of
+// characters, of the tracks of starships and of the heroes of films yields
+// what the memory store holds for the same fixtures.
+func TestMatchesMemory(t *testing.T) {
+	for name, open := range map[string]func(t *testing.T) *sqlite.Store{
+		"new":      openFixtures,
+		"outdated": openOutdated,
+	} {
+		t.Run(name, func(t *testing.T) {
+			matchMemory(t, open(t))
+		})
+	}
+}
+
+func matchMemory(t *testing.T, s *sqlite.Store) {
+	ctx := context.Background()
+	m := memory.New(store.Fixtures())
+
+	humans, err := s.Humans(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHumans, _ := m.Humans(ctx)
+	if !reflect.DeepEqual(humans, wantHumans) {
+		t.Errorf("Humans() = %+v, want %+v", humans, wantHumans)
+	}
+
+	droids, err := s.Droids(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDroids, _ := m.Droids(ctx)
+	if !reflect.DeepEqual(droids, wantDroids) {
+		t.Errorf("Droids() = %+v, want %+v", droids, wantDroids)
+	}
+
+	starships, err := s.Starships(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStarships, _ := m.Starships(ctx)
+	if !reflect.DeepEqual(starships, wantStarships) {
+		t.Errorf("Starships() = %+v, want %+v", starships, wantStarships)
+	}
+
+	films, err := s.Films(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFilms, _ := m.Films(ctx)
+	if !reflect.DeepEqual(films, wantFilms) {
+		t.Errorf("Films() = %+v, want %+v", films, wantFilms)
+	}
+}
@@ -0,0 +1,284 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+)
+
+func (s *Store) CreateHuman(ctx context.Context, h *model.Human) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := nextID(ctx, tx, store.HumanIDBase, "SELECT id FROM characters WHERE kind = 'HUMAN'")
+		if err != nil {
+			return err
+		}
+		h.ID = id
+		if err := insertHuman(ctx, tx, h); err != nil {
+			return err
+		}
+		return mirrorFriends(ctx, tx, id, nil, h.FriendIds)
+	})
+}
+
+func (s *Store) CreateDroid(ctx context.Context, d *model.Droid) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := nextID(ctx, tx, store.DroidIDBase, "SELECT id FROM characters WHERE kind = 'DROID'")
+		if err != nil {
+			return err
+		}
+		d.ID = id
+		if err := insertDroid(ctx, tx, d); err != nil {
+			return err
+		}
+		return mirrorFriends(ctx, tx, id, nil, d.FriendIds)
+	})
+}
+
+func (s *Store) UpdateHuman(ctx context.Context, h *model.Human) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE characters SET name = ?, height_meters = ?, mass = ?, homeworld_id = ?, species_id = ? WHERE id = ? AND kind = 'HUMAN'",
+			h.Name, h.HeightMeters, h.Mass, nullIfEmpty(h.HomeworldID), nullIfEmpty(h.SpeciesID), h.ID)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+		return replaceCharacterFields(ctx, tx, &h.CharacterFields)
+	})
+}
+
+func (s *Store) UpdateDroid(ctx context.Context, d *model.Droid) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE characters SET name = ?, primary_function = ? WHERE id = ? AND kind = 'DROID'",
+			d.Name, d.PrimaryFunction, d.ID)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+		return replaceCharacterFields(ctx, tx, &d.CharacterFields)
+	})
+}
+
+func (s *Store) DeleteCharacter(ctx context.Context, id string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// Rows owned by the character go with it through ON DELETE CASCADE.
+		res, err := tx.ExecContext(ctx, "DELETE FROM characters WHERE id = ?", id)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM character_friends WHERE friend_id = ?", id)
+		return err
+	})
+}
+
+func (s *Store) AddFriendship(ctx context.Context, a, b string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkCharactersExist(ctx, tx, a, b); err != nil {
+			return err
+		}
+		if err := addFriendID(ctx, tx, a, b); err != nil {
+			return err
+		}
+		return addFriendID(ctx, tx, b, a)
+	})
+}
+
+func (s *Store) RemoveFriendship(ctx context.Context, a, b string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkCharactersExist(ctx, tx, a, b); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			"DELETE FROM character_friends WHERE (character_id = ? AND friend_id = ?) OR (character_id = ? AND friend_id = ?)",
+			a, b, b, a)
+		return err
+	})
+}
+
+func (s *Store) CreateStarship(ctx context.Context, sh *model.Starship) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := nextID(ctx, tx, store.StarshipIDBase, "SELECT id FROM starships")
+		if err != nil {
+			return err
+		}
+		sh.ID = id
+		return insertStarship(ctx, tx, sh)
+	})
+}
+
+func (s *Store) UpdateStarship(ctx context.Context, sh *model.Starship) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE starships SET name = ?, length = ? WHERE id = ?",
+			sh.Name, sh.Length, sh.ID)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM starship_history WHERE starship_id = ?", sh.ID); err != nil {
+			return err
+		}
+		return insertHistory(ctx, tx, sh)
+	})
+}
+
+func (s *Store) AppendHistory(ctx context.Context, id string, p model.HistoryPoint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+INSERT INTO starship_history (starship_id, position, x, y, z, timestamp, location_name)
+SELECT id, (SELECT COALESCE(MAX(position) + 1, 0) FROM starship_history WHERE starship_id = ?), ?, ?, ?, ?, ?
+FROM starships WHERE id = ?`,
+			id, p.Coordinate.X, p.Coordinate.Y, p.Coordinate.Z, formatTimestamp(p.Timestamp), p.LocationName, id)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+		return indexPosition(ctx, tx, id)
+	})
+}
+
+func (s *Store) DeleteStarship(ctx context.Context, id string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM starships WHERE id = ?", id)
+		if err := checkAffected(res, err); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM character_starships WHERE starship_id = ?", id); err != nil {
+			return err
+		}
+		return indexPosition(ctx, tx, id)
+	})
+}
+
+// inTx runs fn in a transaction, committing it only if fn succeeds.
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// nextID picks the next free ID of the range at base among the IDs
+// returned by query.
+func nextID(ctx context.Context, db queryer, base int, query string) (string, error) {
+	ids, err := selectStrings(ctx, db, query)
+	if err != nil {
+		return "", err
+	}
+	return store.NextID(base, ids)
+}
+
+// checkAffected turns the outcome of an update or delete touching no row
+// into store.ErrNotFound.
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// checkCharactersExist returns store.ErrNotFound unless every ID is a
+// stored character.
+func checkCharactersExist(ctx context.Context, tx *sql.Tx, ids ...string) error {
+	for _, id := range ids {
+		var n int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM characters WHERE id = ?", id).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return store.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// addFriendID appends friendID to the friend list of id unless it is
+// already there.
+func addFriendID(ctx context.Context, tx *sql.Tx, id, friendID string) error {
+	var n int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM character_friends WHERE character_id = ? AND friend_id = ?",
+		id, friendID).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO character_friends (character_id, position, friend_id)
+		SELECT ?, COALESCE(MAX(position) + 1, 0), ? FROM character_friends WHERE character_id = ?`,
+		id, friendID, id)
+	return err
+}
+
+// replaceCharacterFields replaces the lists of the stored character of c
+// with those of c, keeping its friendships symmetric.
+func replaceCharacterFields(ctx context.Context, tx *sql.Tx, c *model.CharacterFields) error {
+	old, err := selectStrings(ctx, tx, "SELECT friend_id FROM character_friends WHERE character_id = ?", c.ID)
+	if err != nil {
+		return err
+	}
+	if err := deleteCharacterRelations(ctx, tx, c.ID); err != nil {
+		return err
+	}
+	if err := insertCharacterFields(ctx, tx, c); err != nil {
+		return err
+	}
+	return mirrorFriends(ctx, tx, c.ID, old, c.FriendIds)
+}
+
+// mirrorFriends keeps the friendships of id symmetric once its friend list
+// went from old to friends: its friends list it back, and the characters
+// it dropped drop it too. Unknown friends are skipped.
+func mirrorFriends(ctx context.Context, tx *sql.Tx, id string, old, friends []string) error {
+	for _, f := range old {
+		if slices.Contains(friends, f) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM character_friends WHERE character_id = ? AND friend_id = ?", f, id); err != nil {
+			return err
+		}
+	}
+	for _, f := range friends {
+		if f == id {
+			continue
+		}
+		if err := checkCharactersExist(ctx, tx, f); errors.Is(err, store.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := addFriendID(ctx, tx, f, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteCharacterRelations(ctx context.Context, db execer, id string) error {
+	for _, query := range []string{
+		"DELETE FROM character_friends WHERE character_id = ?",
+		"DELETE FROM character_episodes WHERE character_id = ?",
+		"DELETE FROM character_starships WHERE character_id = ?",
+		"DELETE FROM character_vehicles WHERE character_id = ?",
+	} {
+		if _, err := db.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package store defines the persistence boundary of the Star Wars graph.
+// Resolvers only talk to the repositories declared here, so the backing
+// storage can be swapped without touching resolver code.
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+)
+
+// ErrNotFound is returned when updating or deleting an unknown entity.
+var ErrNotFound = errors.New("not found")
+
+// CharacterRepository gives access to humans and droids.
+// Lookups of unknown IDs return a nil value and a nil error.
+// Creating or updating a character keeps its friendships symmetric: the
+// characters it lists as friends list it back, and the ones it stops
+// listing drop it from their own list.
+type CharacterRepository interface {
+	Human(ctx context.Context, id string) (*model.Human, error)
+	Droid(ctx context.Context, id string) (*model.Droid, error)
+	// Humans returns every human ordered by ID.
+	Humans(ctx context.Context) ([]*model.Human, error)
+	// Droids returns every droid ordered by ID.
+	Droids(ctx context.Context) ([]*model.Droid, error)
+	// CharactersByIDs looks up the humans and droids of ids in one go. The
+	// result is aligned with ids and holds nil for unknown IDs.
+	CharactersByIDs(ctx context.Context, ids []string) ([]model.Character, error)
+
+	// CreateHuman stores h under the next free ID of the human range
+	// and sets h.ID accordingly.
+	CreateHuman(ctx context.Context, h *model.Human) error
+	// CreateDroid stores d under the next free ID of the droid range
+	// and sets d.ID accordingly.
+	CreateDroid(ctx context.Context, d *model.Droid) error
+	// UpdateHuman replaces the stored human with the same ID.
+	UpdateHuman(ctx context.Context, h *model.Human) error
+	// UpdateDroid replaces the stored droid with the same ID.
+	UpdateDroid(ctx context.Context, d *model.Droid) error
+	// DeleteCharacter removes a human or droid and drops its ID from the
+	// friend lists of every other character.
+	DeleteCharacter(ctx context.Context, id string) error
+
+	// AddFriendship appends a and b to each other's friend list, leaving a
+	// list alone when it already holds the other ID.
+	AddFriendship(ctx context.Context, a, b string) error
+	// RemoveFriendship drops a and b from each other's friend list.
+	RemoveFriendship(ctx context.Context, a, b string) error
+}
+
+// StarshipRepository gives access to starships.
+// Lookups of unknown IDs return a nil value and a nil error.
+// The x and y coordinates of tracks must fit in 32 bits.
+type StarshipRepository interface {
+	Starship(ctx context.Context, id string) (*model.Starship, error)
+	// Starships returns every starship ordered by ID.
+	Starships(ctx context.Context) ([]*model.Starship, error)
+	// StarshipsByIDs looks up the starships of ids in one go. The result is
+	// aligned with ids and holds nil for unknown IDs.
+	StarshipsByIDs(ctx context.Context, ids []string) ([]*model.Starship, error)
+	// StarshipsNear returns the starships whose last known position lies
+	// within radius of x, y on the map plane, nearest first and then by ID.
+	StarshipsNear(ctx context.Context, x, y int, radius float64) ([]*model.Starship, error)
+	// StarshipsInRegion returns the starships whose last known position
+	// lies in box, ordered by ID.
+	StarshipsInRegion(ctx context.Context, box model.BoundingBox) ([]*model.Starship, error)
+	// StarshipPilotIDs returns, for each starship of ids, the IDs of the
+	// humans and droids flying it, ordered by ID.
+	StarshipPilotIDs(ctx context.Context, ids []string) ([][]string, error)
+
+	// CreateStarship stores s under the next free ID of the starship range
+	// and sets s.ID accordingly.
+	CreateStarship(ctx context.Context, s *model.Starship) error
+	// UpdateStarship replaces the stored starship with the same ID.
+	UpdateStarship(ctx context.Context, s *model.Starship) error
+	// AppendHistory adds p at the end of the track of the starship id.
+	AppendHistory(ctx context.Context, id string, p model.HistoryPoint) error
+	// DeleteStarship removes a starship and drops its ID from the
+	// starships of every character.
+	DeleteStarship(ctx context.Context, id string) error
+}
+
+// VehicleRepository gives access to ground and atmospheric vehicles.
+// Lookups of unknown IDs return a nil value and a nil error.
+type VehicleRepository interface {
+	Vehicle(ctx context.Context, id string) (*model.Vehicle, error)
+	// Vehicles returns every vehicle ordered by ID.
+	Vehicles(ctx context.Context) ([]*model.Vehicle, error)
+	// VehiclesByIDs looks up the vehicles of ids in one go. The result is
+	// aligned with ids and holds nil for unknown IDs.
+	VehiclesByIDs(ctx context.Context, ids []string) ([]*model.Vehicle, error)
+	// VehiclePilotIDs returns, for each vehicle of ids, the IDs of the
+	// humans and droids driving it, ordered by ID.
+	VehiclePilotIDs(ctx context.Context, ids []string) ([][]string, error)
+}
+
+// ReviewRepository stores the reviews posted for each episode.
+type ReviewRepository interface {
+	// Reviews returns the reviews of an episode in the order they were added.
+	Reviews(ctx context.Context, episode model.Episode) ([]*model.Review, error)
+	// ReviewsByIDs looks up the reviews of ids in one go. The result is
+	// aligned with ids and holds nil for unknown IDs.
+	ReviewsByIDs(ctx context.Context, ids []string) ([]*model.Review, error)
+	// AddReview stores review, sets review.ID to a fresh unique ID and
+	// review.Episode to episode.
+	AddReview(ctx context.Context, episode model.Episode, review *model.Review) error
+}
+
+// PlanetRepository gives access to planets.
+// Lookups of unknown IDs return a nil value and a nil error.
+type PlanetRepository interface {
+	Planet(ctx context.Context, id string) (*model.Planet, error)
+	// Planets returns every planet ordered by ID.
+	Planets(ctx context.Context) ([]*model.Planet, error)
+	// PlanetsByIDs looks up the planets of ids in one go. The result is
+	// aligned with ids and holds nil for unknown IDs.
+	PlanetsByIDs(ctx context.Context, ids []string) ([]*model.Planet, error)
+	// ResidentIDs returns, for each planet of ids, the IDs of the humans
+	// whose homeworld it is, ordered by ID.
+	ResidentIDs(ctx context.Context, ids []string) ([][]string, error)
+}
+
+// SpeciesRepository gives access to species.
+// Lookups of unknown IDs return a nil value and a nil error.
+type SpeciesRepository interface {
+	Species(ctx context.Context, id string) (*model.Species, error)
+	// AllSpecies returns every species ordered by ID.
+	AllSpecies(ctx context.Context) ([]*model.Species, error)
+	// SpeciesByIDs looks up the species of ids in one go. The result is
+	// aligned with ids and holds nil for unknown IDs.
+	SpeciesByIDs(ctx context.Context, ids []string) ([]*model.Species, error)
+}
+
+// FilmRepository gives access to the movies of the saga, along with the
+// heroes of each episode. Deleting a character drops it from the heroes.
+// Lookups of unknown episodes return a nil value and a nil error.
+type FilmRepository interface {
+	Film(ctx context.Context, episode model.Episode) (*model.Film, error)
+	// Films returns every film ordered by episode number.
+	Films(ctx context.Context) ([]*model.Film, error)
+	// FilmsByEpisodes looks up the films of episodes in one go. The result
+	// is aligned with episodes and holds nil for unknown episodes.
+	FilmsByEpisodes(ctx context.Context, episodes []model.Episode) ([]*model.Film, error)
+}
+
+// Repository is the set of repositories a resolver is built from.
+type Repository struct {
+	Characters CharacterRepository
+	Starships  StarshipRepository
+	Vehicles   VehicleRepository
+	Reviews    ReviewRepository
+	Films      FilmRepository
+	Planets    PlanetRepository
+	Species    SpeciesRepository
+}
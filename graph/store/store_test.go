@@ -0,0 +1,91 @@
+package store_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/MatsuoTakuro/starwars/graph/model"
+	"github.com/MatsuoTakuro/starwars/graph/store/storetest"
+)
+
+// The last known positions of the fixture starships are:
+//
+//	3000 Millennium Falcon  3, 2
+//	3001 X-Wing             5, 1
+//	3002 TIE Advanced x1    3, 2
+//	3003 Imperial shuttle   7, 1
+
+func ids(starships []*model.Starship) []string {
+	l := []string{}
+	for _, s := range starships {
+		l = append(l, s.ID)
+	}
+	return l
+}
+
+func TestStarshipsNear(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   int
+		radius float64
+		want   []string
+	}{
+		{name: "exact position", x: 3, y: 2, radius: 0, want: []string{"3000", "3002"}},
+		{name: "nearest first", x: 4, y: 1, radius: 2, want: []string{"3001", "3000", "3002"}},
+		{name: "edge of the disc", x: 5, y: 1, radius: 2, want: []string{"3001", "3003"}},
+		{name: "nothing", x: -10, y: -10, radius: 5, want: []string{}},
+		{name: "huge radius", x: 0, y: 0, radius: math.MaxFloat64, want: []string{"3000", "3002", "3001", "3003"}},
+		{name: "far center", x: math.MaxInt, y: math.MinInt, radius: 10, want: []string{}},
+	}
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			s := storetest.Open(t, backend).Starships
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, err := s.StarshipsNear(context.Background(), tt.x, tt.y, tt.radius)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(ids(got), tt.want) {
+						t.Errorf("StarshipsNear(%d, %d, %g) = %v, want %v", tt.x, tt.y, tt.radius, ids(got), tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestStarshipsInRegion(t *testing.T) {
+	tests := []struct {
+		name string
+		box  model.BoundingBox
+		want []string
+	}{
+		{name: "single point", box: model.BoundingBox{MinX: 3, MinY: 2, MaxX: 3, MaxY: 2}, want: []string{"3000", "3002"}},
+		{name: "corners included", box: model.BoundingBox{MinX: 5, MinY: 1, MaxX: 7, MaxY: 1}, want: []string{"3001", "3003"}},
+		{name: "older positions ignored", box: model.BoundingBox{MinX: 0, MinY: 3, MaxX: 10, MaxY: 10}, want: []string{}},
+		{name: "inverted", box: model.BoundingBox{MinX: 7, MinY: 1, MaxX: 3, MaxY: 2}, want: []string{}},
+		{name: "whole map", box: model.BoundingBox{MinX: math.MinInt32, MinY: math.MinInt32, MaxX: math.MaxInt32, MaxY: math.MaxInt32},
+			want: []string{"3000", "3001", "3002", "3003"}},
+		{name: "beyond 32 bits", box: model.BoundingBox{MinX: math.MinInt, MinY: math.MinInt, MaxX: math.MaxInt, MaxY: math.MaxInt},
+			want: []string{"3000", "3001", "3002", "3003"}},
+	}
+	for _, backend := range storetest.Backends {
+		t.Run(backend, func(t *testing.T) {
+			s := storetest.Open(t, backend).Starships
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, err := s.StarshipsInRegion(context.Background(), tt.box)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(ids(got), tt.want) {
+						t.Errorf("StarshipsInRegion(%+v) = %v, want %v", tt.box, ids(got), tt.want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Package storetest opens the stores tests run against, seeded with the
+// fixtures, so that every test can cover each backend alike.
+package storetest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatsuoTakuro/starwars/graph/store"
+	"github.com/MatsuoTakuro/starwars/graph/store/memory"
+	"github.com/MatsuoTakuro/starwars/graph/store/sqlite"
+)
+
+// Backends names every backend Open knows.
+var Backends = []string{"memory", "sqlite"}
+
+// Open returns a fresh repository of backend seeded with the fixtures.
+// SQLite databases live in a temporary directory removed with the test.
+func Open(t testing.TB, backend string) store.Repository {
+	t.Helper()
+	switch backend {
+	case "memory":
+		return memory.New(store.Fixtures()).Repository()
+	case "sqlite":
+		return OpenSQLite(t, filepath.Join(t.TempDir(), "starwars.db")).Repository()
+	default:
+		t.Fatalf("unknown backend %q", backend)
+		return store.Repository{}
+	}
+}
+
+// OpenSQLite opens the database at path and seeds it with the fixtures, as
+// the server does on start. The store is closed with the test.
+func OpenSQLite(t testing.TB, path string) *sqlite.Store {
+	t.Helper()
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if err := s.Seed(ctx, store.Fixtures()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,124 @@
+// Package units converts the lengths and masses of the dataset, stored in
+// meters and kilograms, to the units clients ask for. Its unit types back
+// the LengthUnit and MassUnit enums of the schema.
+package units
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// LengthUnit is a unit lengths can be given in.
+type LengthUnit string
+
+const (
+	Meter      LengthUnit = "METER"
+	Foot       LengthUnit = "FOOT"
+	Inch       LengthUnit = "INCH"
+	Centimeter LengthUnit = "CENTIMETER"
+	Kilometer  LengthUnit = "KILOMETER"
+	LightYear  LengthUnit = "LIGHT_YEAR"
+)
+
+// MetersPerLightYear is the length of a Julian light-year, which is also
+// the unit of the galaxy map.
+const MetersPerLightYear = 9.4607304725808e15
+
+// metersPer is the size of each length unit in meters.
+var metersPer = map[LengthUnit]float64{
+	Meter:      1,
+	Foot:       0.3048,
+	Inch:       0.0254,
+	Centimeter: 0.01,
+	Kilometer:  1000,
+	LightYear:  MetersPerLightYear,
+}
+
+// MassUnit is a unit masses can be given in.
+type MassUnit string
+
+const (
+	Kilogram MassUnit = "KILOGRAM"
+	Pound    MassUnit = "POUND"
+)
+
+// kilogramsPer is the mass of each mass unit in kilograms.
+var kilogramsPer = map[MassUnit]float64{
+	Kilogram: 1,
+	Pound:    0.45359237,
+}
+
+// Length converts a length in meters to unit. A nil unit stands for
+// meters.
+func Length(meters float64, unit *LengthUnit) (float64, error) {
+	if unit == nil {
+		return meters, nil
+	}
+	f, ok := metersPer[*unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown length unit %q", string(*unit))
+	}
+	return meters / f, nil
+}
+
+// Mass converts a mass in kilograms to unit. A nil unit stands for
+// kilograms.
+func Mass(kilograms float64, unit *MassUnit) (float64, error) {
+	if unit == nil {
+		return kilograms, nil
+	}
+	f, ok := kilogramsPer[*unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown mass unit %q", string(*unit))
+	}
+	return kilograms / f, nil
+}
+
+func (u LengthUnit) IsValid() bool {
+	_, ok := metersPer[u]
+	return ok
+}
+
+func (u LengthUnit) String() string {
+	return string(u)
+}
+
+func (u *LengthUnit) UnmarshalGQL(v interface{}) error {
+	return unmarshalEnum(v, (*string)(u), "LengthUnit", func() bool { return u.IsValid() })
+}
+
+func (u LengthUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(u.String()))
+}
+
+func (u MassUnit) IsValid() bool {
+	_, ok := kilogramsPer[u]
+	return ok
+}
+
+func (u MassUnit) String() string {
+	return string(u)
+}
+
+func (u *MassUnit) UnmarshalGQL(v interface{}) error {
+	return unmarshalEnum(v, (*string)(u), "MassUnit", func() bool { return u.IsValid() })
+}
+
+func (u MassUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(u.String()))
+}
+
+// unmarshalEnum stores the enum value v in dst, failing unless valid
+// accepts it.
+func unmarshalEnum(v interface{}, dst *string, name string, valid func() bool) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+	*dst = s
+	if !valid() {
+		return fmt.Errorf("%s is not a valid %s", s, name)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package units
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// near reports whether got is within rounding of want.
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-12*math.Max(1, math.Abs(want))
+}
+
+func TestLength(t *testing.T) {
+	unit := func(u LengthUnit) *LengthUnit { return &u }
+
+	tests := []struct {
+		name   string
+		meters float64
+		unit   *LengthUnit
+		want   float64
+	}{
+		{name: "nil", meters: 34.37, want: 34.37},
+		{name: "meter", meters: 34.37, unit: unit(Meter), want: 34.37},
+		{name: "foot", meters: 0.3048, unit: unit(Foot), want: 1},
+		{name: "inch", meters: 1, unit: unit(Inch), want: 1 / 0.0254},
+		{name: "centimeter", meters: 1.72, unit: unit(Centimeter), want: 172},
+		{name: "kilometer", meters: 19000, unit: unit(Kilometer), want: 19},
+		{name: "light year", meters: 2 * MetersPerLightYear, unit: unit(LightYear), want: 2},
+		{name: "zero", meters: 0, unit: unit(Foot), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Length(tt.meters, tt.unit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(got, tt.want) {
+				t.Errorf("Length(%g) = %g, want %g", tt.meters, got, tt.want)
+			}
+		})
+	}
+
+	// Every unit of the enum has a conversion.
+	for u := range metersPer {
+		if _, err := Length(1, &u); err != nil {
+			t.Errorf("Length(1, %s): %v", u, err)
+		}
+	}
+	if _, err := Length(1, unit("PARSEC")); err == nil {
+		t.Error("Length(1, PARSEC) succeeded, want an error")
+	}
+}
+
+func TestMass(t *testing.T) {
+	unit := func(u MassUnit) *MassUnit { return &u }
+
+	tests := []struct {
+		name      string
+		kilograms float64
+		unit      *MassUnit
+		want      float64
+	}{
+		{name: "nil", kilograms: 77, want: 77},
+		{name: "kilogram", kilograms: 77, unit: unit(Kilogram), want: 77},
+		{name: "pound", kilograms: 0.45359237, unit: unit(Pound), want: 1},
+		{name: "pounds", kilograms: 136, unit: unit(Pound), want: 136 / 0.45359237},
+		{name: "zero", kilograms: 0, unit: unit(Pound), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Mass(tt.kilograms, tt.unit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(got, tt.want) {
+				t.Errorf("Mass(%g) = %g, want %g", tt.kilograms, got, tt.want)
+			}
+		})
+	}
+
+	for u := range kilogramsPer {
+		if _, err := Mass(1, &u); err != nil {
+			t.Errorf("Mass(1, %s): %v", u, err)
+		}
+	}
+	if _, err := Mass(1, unit("STONE")); err == nil {
+		t.Error("Mass(1, STONE) succeeded, want an error")
+	}
+}
+
+func TestMarshalGQL(t *testing.T) {
+	for u := range metersPer {
+		var buf bytes.Buffer
+		u.MarshalGQL(&buf)
+		if want := strconv.Quote(string(u)); buf.String() != want {
+			t.Errorf("%s.MarshalGQL() = %s, want %s", u, buf.String(), want)
+		}
+	}
+	for u := range kilogramsPer {
+		var buf bytes.Buffer
+		u.MarshalGQL(&buf)
+		if want := strconv.Quote(string(u)); buf.String() != want {
+			t.Errorf("%s.MarshalGQL() = %s, want %s", u, buf.String(), want)
+		}
+	}
+}
+
+func TestUnmarshalGQL(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		length  LengthUnit
+		mass    MassUnit
+		wantErr string
+	}{
+		{v: "METER", length: Meter, wantErr: "METER is not a valid MassUnit"},
+		{v: "FOOT", length: Foot, wantErr: "FOOT is not a valid MassUnit"},
+		{v: "INCH", length: Inch, wantErr: "INCH is not a valid MassUnit"},
+		{v: "CENTIMETER", length: Centimeter, wantErr: "CENTIMETER is not a valid MassUnit"},
+		{v: "KILOMETER", length: Kilometer, wantErr: "KILOMETER is not a valid MassUnit"},
+		{v: "LIGHT_YEAR", length: LightYear, wantErr: "LIGHT_YEAR is not a valid MassUnit"},
+		{v: "KILOGRAM", mass: Kilogram, wantErr: "KILOGRAM is not a valid LengthUnit"},
+		{v: "POUND", mass: Pound, wantErr: "POUND is not a valid LengthUnit"},
+		{v: "meter", wantErr: "meter is not a valid"},
+		{v: "PARSEC", wantErr: "PARSEC is not a valid"},
+		{v: "", wantErr: " is not a valid"},
+		{v: 1, wantErr: "enums must be strings"},
+		{v: nil, wantErr: "enums must be strings"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.v), func(t *testing.T) {
+			var l LengthUnit
+			err := l.UnmarshalGQL(tt.v)
+			if tt.length != "" {
+				if err != nil || l != tt.length {
+					t.Errorf("LengthUnit.UnmarshalGQL(%#v) = %s, %v, want %s", tt.v, l, err, tt.length)
+				}
+			} else if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("LengthUnit.UnmarshalGQL(%#v) error = %v, want %q", tt.v, err, tt.wantErr)
+			}
+
+			var m MassUnit
+			err = m.UnmarshalGQL(tt.v)
+			if tt.mass != "" {
+				if err != nil || m != tt.mass {
+					t.Errorf("MassUnit.UnmarshalGQL(%#v) = %s, %v, want %s", tt.v, m, err, tt.mass)
+				}
+			} else if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("MassUnit.UnmarshalGQL(%#v) error = %v, want %q", tt.v, err, tt.wantErr)
+			}
+		})
+	}
+}
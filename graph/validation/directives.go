@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Range implements the @range directive.
+func Range(ctx context.Context, _ interface{}, next graphql.Resolver, min *float64, max *float64) (interface{}, error) {
+	v, err := next(ctx)
+	if err != nil {
+		return v, err
+	}
+
+	n, ok := number(v)
+	if !ok {
+		return v, nil
+	}
+	if min != nil && n < *min || max != nil && n > *max {
+		return v, Errorf(inputField(ctx), "%s must be %s, got %s",
+			fieldName(ctx), bounds(min, max), strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	return v, nil
+}
+
+// Length implements the @length directive.
+func Length(ctx context.Context, _ interface{}, next graphql.Resolver, min *int, max *int) (interface{}, error) {
+	v, err := next(ctx)
+	if err != nil {
+		return v, err
+	}
+
+	n, unit, ok := length(v)
+	if !ok {
+		return v, nil
+	}
+	if min != nil && n < *min {
+		return v, Errorf(inputField(ctx), "%s must be at least %d %s, got %d", fieldName(ctx), *min, unit, n)
+	}
+	if max != nil && n > *max {
+		return v, Errorf(inputField(ctx), "%s must be at most %d %s, got %d", fieldName(ctx), *max, unit, n)
+	}
+	return v, nil
+}
+
+// number reads v as a float, skipping null values.
+func number(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return 0, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// length measures strings in characters and lists in items, skipping
+// null values. It also returns the unit the length is counted in.
+func length(v interface{}) (int, string, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return 0, "", false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(rv.String()), "characters", true
+	case reflect.Slice:
+		if rv.IsNil() {
+			return 0, "", false
+		}
+		return rv.Len(), "items", true
+	}
+	return 0, "", false
+}
+
+func bounds(min, max *float64) string {
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	switch {
+	case min != nil && max != nil:
+		return fmt.Sprintf("between %s and %s", format(*min), format(*max))
+	case min != nil:
+		return "at least " + format(*min)
+	default:
+		return "at most " + format(*max)
+	}
+}
+
+// inputPath is the path of the value being validated within the field
+// arguments, such as review.stars.
+func inputPath(ctx context.Context) ast.Path {
+	var path ast.Path
+	for pc := graphql.GetPathContext(ctx); pc != nil; pc = pc.Parent {
+		switch {
+		case pc.Index != nil:
+			path = append(ast.Path{ast.PathIndex(*pc.Index)}, path...)
+		case pc.Field != nil:
+			path = append(ast.Path{ast.PathName(*pc.Field)}, path...)
+		}
+	}
+	return path
+}
+
+// inputField renders inputPath the way it is exposed as extensions.field.
+func inputField(ctx context.Context) string {
+	var b strings.Builder
+	for _, p := range inputPath(ctx) {
+		switch p := p.(type) {
+		case ast.PathIndex:
+			fmt.Fprintf(&b, "[%d]", int(p))
+		case ast.PathName:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(string(p))
+		}
+	}
+	return b.String()
+}
+
+// fieldName is the innermost field name of inputPath.
+func fieldName(ctx context.Context) string {
+	path := inputPath(ctx)
+	for i := len(path) - 1; i >= 0; i-- {
+		if name, ok := path[i].(ast.PathName); ok {
+			return string(name)
+		}
+	}
+	return "value"
+}
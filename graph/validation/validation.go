@@ -0,0 +1,39 @@
+// Package validation reports invalid user input as GraphQL errors that
+// clients can map back to the offending input field.
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeBadUserInput is the extensions.code of every validation error.
+const CodeBadUserInput = "BAD_USER_INPUT"
+
+// Errorf builds the error reported for the input field at path, such as
+// "review.stars". The path is exposed as extensions.field.
+func Errorf(field string, format string, args ...interface{}) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: fmt.Sprintf(format, args...),
+		Extensions: map[string]interface{}{
+			"code":  CodeBadUserInput,
+			"field": field,
+		},
+	}
+}
+
+// Join adds all but the last of errs to the response and returns the last
+// one, so a resolver returning it reports every error in order. It returns
+// nil when errs is empty.
+func Join(ctx context.Context, errs []*gqlerror.Error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs[:len(errs)-1] {
+		graphql.AddError(ctx, err)
+	}
+	return errs[len(errs)-1]
+}
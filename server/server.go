@@ -6,15 +6,27 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/MatsuoTakuro/starwars/graph/generated"
+	"github.com/MatsuoTakuro/starwars/graph/limits"
+	"github.com/MatsuoTakuro/starwars/graph/loader"
 	"github.com/MatsuoTakuro/starwars/graph/resolver"
+	"github.com/MatsuoTakuro/starwars/graph/store"
+	"github.com/MatsuoTakuro/starwars/graph/store/memory"
+	"github.com/MatsuoTakuro/starwars/graph/store/sqlite"
 )
 
 const defaultPort = "8082"
+const defaultSQLitePath = "starwars.db"
+const defaultLatency = time.Second
+const defaultMaxDepth = 15 // the playground introspects 13 fields deep
+const defaultMaxComplexity = 500
 const title = "gqlgen-starwars"
 
 func main() {
@@ -23,7 +35,21 @@ func main() {
 		port = defaultPort
 	}
 
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(resolver.NewResolver()))
+	repo, err := newRepository(context.Background(), os.Getenv("STORE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	latency := defaultLatency
+	if v := os.Getenv("LATENCY"); v != "" {
+		if latency, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid LATENCY: %v", err)
+		}
+	}
+
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(resolver.NewResolver(repo, resolver.WithLatency(latency))))
+	srv.Use(limits.FixedDepthLimit(envInt("MAX_DEPTH", defaultMaxDepth)))
+	srv.Use(extension.FixedComplexityLimit(envInt("MAX_COMPLEXITY", defaultMaxComplexity)))
 	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (res interface{}, err error) {
 		rc := graphql.GetFieldContext(ctx)
 		fmt.Println("\nEntered", rc.Object, rc.Field.Name)
@@ -33,8 +59,48 @@ func main() {
 	})
 
 	http.Handle("/", playground.Handler(title, "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", loader.Middleware(repo, srv))
 
 	log.Printf("connect to http://localhost:%s/ for %s", port, title)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// envInt reads the positive integer held by the environment variable name,
+// falling back to def when it is unset.
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Fatalf("invalid %s: want a positive integer, got %q", name, v)
+	}
+	return n
+}
+
+// newRepository builds the store selected by kind, seeded with the fixtures.
+// The SQLite database file is taken from SQLITE_PATH.
+func newRepository(ctx context.Context, kind string) (store.Repository, error) {
+	switch kind {
+	case "memory", "":
+		return memory.New(store.Fixtures()).Repository(), nil
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = defaultSQLitePath
+		}
+		s, err := sqlite.Open(ctx, path)
+		if err != nil {
+			return store.Repository{}, err
+		}
+		if err := s.Seed(ctx, store.Fixtures()); err != nil {
+			s.Close()
+			return store.Repository{}, err
+		}
+		log.Printf("using sqlite store at %s", path)
+		return s.Repository(), nil
+	default:
+		return store.Repository{}, fmt.Errorf("unknown STORE %q, want memory or sqlite", kind)
+	}
+}